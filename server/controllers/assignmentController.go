@@ -0,0 +1,239 @@
+package controller
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	database "task-manager/server/database"
+	helper "task-manager/server/helpers"
+	model "task-manager/server/models"
+)
+
+// assignedToFilter - Matches the tasks assigned to the user that they have not declined
+func assignedToFilter(userID string) bson.M {
+	return bson.M{"assignee_id": userID, "assignment_status": bson.M{"$in": model.ActiveAssignments}}
+}
+
+// notifyAssignee - Emails the assignee about a task handed to them. A failed email does not undo the assignment.
+func notifyAssignee(assignee model.User, assigner string, task model.Task) {
+	if assignee.Email == nil {
+		return
+	}
+	subject := "Task assigned to you: " + task.Title
+	body := assigner + " assigned you the task \"" + task.Title + "\". Accept or decline it in My Task Manager."
+	if task.DueAt != nil {
+		body += "\n\nIt is due " + task.DueAt.Format(time.RFC1123) + "."
+	}
+	if err := helper.SendEmail(*assignee.Email, subject, body); err != nil {
+		log.Printf("Error notifying assignee of task %s: %v", task.ID.Hex(), err)
+	}
+}
+
+// AssignTask - Hands a task to another user, identified by user_id or username, who then accepts or declines it.
+// Users assigning a task to themselves accept it straight away.
+func AssignTask() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		var request struct {
+			UserID   string `json:"user_id"`
+			Username string `json:"username"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid JSON input", err.Error())
+			return
+		}
+		if (request.UserID == "") == (request.Username == "") {
+			helper.RespondWithError(c, http.StatusBadRequest, "Validation error", "Give exactly one of user_id or username")
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		current, err := findAccessibleTask(ctx, userID, id, model.RoleEditor)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching task")
+			return
+		}
+		assignee, err := findUser(ctx, request.UserID, request.Username)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching user")
+			return
+		}
+		assigneeID := *assignee.UserID
+		if err := checkWorkspaceMember(ctx, current.WorkspaceID, assigneeID); err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching member")
+			return
+		}
+
+		status := model.AssignmentPending
+		if assigneeID == userID {
+			status = model.AssignmentAccepted
+		}
+
+		// Matching on updated_at keeps two people from handing the task out at the same time
+		now := time.Now().UTC()
+		var task model.Task
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		err = database.GetTaskCollection().FindOneAndUpdate(ctx,
+			helper.NotDeleted(helper.InWorkspace(ctx, bson.M{"_id": id, "user_id": current.UserID, "updated_at": current.Updated})),
+			bson.M{"$set": bson.M{
+				"assignee_id":       assigneeID,
+				"assignment_status": status,
+				"assigned_by":       userID,
+				"assigned_at":       now,
+				"updated_at":        now,
+			}},
+			opts,
+		).Decode(&task)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				helper.RespondWithError(c, http.StatusConflict, "Task was modified concurrently", "The task changed while assigning it, please retry")
+				return
+			}
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error assigning task", err.Error())
+			return
+		}
+
+		if status == model.AssignmentPending {
+			notifyAssignee(assignee, username, task)
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Task assigned successfully by "+username, task)
+	}
+}
+
+// UnassignTask - Takes a task back from whoever it is assigned to
+func UnassignTask() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		current, err := findAccessibleTask(ctx, userID, id, model.RoleEditor)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching task")
+			return
+		}
+		if current.AssigneeID == nil {
+			helper.RespondWithSuccess(c, http.StatusOK, "Task is not assigned for "+username, current)
+			return
+		}
+
+		var task model.Task
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		err = database.GetTaskCollection().FindOneAndUpdate(ctx,
+			helper.NotDeleted(helper.InWorkspace(ctx, bson.M{"_id": id, "user_id": current.UserID})),
+			bson.M{
+				"$set":   bson.M{"updated_at": time.Now().UTC()},
+				"$unset": bson.M{"assignee_id": "", "assignment_status": "", "assigned_by": "", "assigned_at": ""},
+			},
+			opts,
+		).Decode(&task)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				helper.RespondWithError(c, http.StatusNotFound, "Task not found", "No task found for the specified ID and user")
+				return
+			}
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error unassigning task", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Task unassigned successfully by "+username, task)
+	}
+}
+
+// answerAssignment - Records the assignee's answer to a pending assignment
+func answerAssignment(ctx context.Context, userID string, id primitive.ObjectID, answer model.AssignmentStatus) (model.Task, error) {
+	collection := database.GetTaskCollection()
+
+	var task model.Task
+	err := collection.FindOne(ctx, helper.NotDeleted(helper.InWorkspace(ctx, bson.M{"_id": id, "assignee_id": userID}))).Decode(&task)
+	if err == mongo.ErrNoDocuments {
+		return task, helper.NewRequestError(http.StatusNotFound, "Assignment not found", "No task assigned to you for the specified ID")
+	} else if err != nil {
+		return task, err
+	}
+	if task.AssignmentStatus != model.AssignmentPending {
+		return task, helper.NewRequestError(http.StatusConflict, "Assignment already answered", "The assignment was already "+string(task.AssignmentStatus))
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = collection.FindOneAndUpdate(ctx,
+		helper.NotDeleted(helper.InWorkspace(ctx, bson.M{"_id": id, "assignee_id": userID, "assignment_status": model.AssignmentPending})),
+		bson.M{"$set": bson.M{"assignment_status": answer, "updated_at": time.Now().UTC()}},
+		opts,
+	).Decode(&task)
+	if err == mongo.ErrNoDocuments {
+		return task, helper.NewRequestError(http.StatusConflict, "Assignment changed", "The assignment changed while answering it, please retry")
+	}
+	return task, err
+}
+
+// respondToAssignment - Builds the handler for accepting or declining an assignment
+func respondToAssignment(answer model.AssignmentStatus) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		task, err := answerAssignment(ctx, userID, id, answer)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error answering assignment")
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Assignment "+string(answer)+" by "+username, task)
+	}
+}
+
+// AcceptAssignment - Lets the assignee take on a task assigned to them
+func AcceptAssignment() gin.HandlerFunc {
+	return respondToAssignment(model.AssignmentAccepted)
+}
+
+// DeclineAssignment - Lets the assignee turn down a task assigned to them, which also removes their access to it
+func DeclineAssignment() gin.HandlerFunc {
+	return respondToAssignment(model.AssignmentDeclined)
+}
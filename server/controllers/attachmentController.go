@@ -0,0 +1,293 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	database "task-manager/server/database"
+	helper "task-manager/server/helpers"
+	model "task-manager/server/models"
+	storage "task-manager/server/storage"
+)
+
+// maxAttachmentSize - The largest file that can be attached to a task, in bytes
+const maxAttachmentSize = 10 << 20
+
+// maxTaskAttachments - How many files one task can carry
+const maxTaskAttachments = 20
+
+// allowedAttachmentTypes - The content types accepted for attachments. The type is sniffed from the file
+// itself rather than taken from the upload, so a renamed file cannot slip through.
+var allowedAttachmentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"text/plain":      true,
+}
+
+// findAttachment - Looks up an attachment of the task by its ID
+func findAttachment(task model.Task, id primitive.ObjectID) (model.Attachment, error) {
+	for _, attachment := range task.Attachments {
+		if attachment.ID == id {
+			return attachment, nil
+		}
+	}
+	return model.Attachment{}, helper.NewRequestError(http.StatusNotFound, "Attachment not found", "No attachment found for the specified ID and task")
+}
+
+// parseAttachmentIDs - Reads the task and attachment IDs from the path
+func parseAttachmentIDs(c *gin.Context) (primitive.ObjectID, primitive.ObjectID, bool) {
+	taskID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+		return taskID, taskID, false
+	}
+	attachmentID, err := primitive.ObjectIDFromHex(c.Param("attachment_id"))
+	if err != nil {
+		helper.RespondWithError(c, http.StatusBadRequest, "Invalid attachment ID format", err.Error())
+		return taskID, attachmentID, false
+	}
+	return taskID, attachmentID, true
+}
+
+// GetAttachments - Lists the files attached to a task
+func GetAttachments() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		taskID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		task, err := findAccessibleTask(ctx, userID, taskID, model.RoleViewer)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching task")
+			return
+		}
+
+		attachments := task.Attachments
+		if attachments == nil {
+			attachments = []model.Attachment{}
+		}
+		helper.RespondWithSuccess(c, http.StatusOK, "Attachments for "+username, attachments)
+	}
+}
+
+// PostAttachment - Attaches the file sent as the "file" field of a multipart form to a task
+func PostAttachment() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		taskID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		// Leave room for the multipart framing around the file
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxAttachmentSize+64<<10)
+		file, header, err := c.Request.FormFile("file")
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				helper.RespondWithError(c, http.StatusRequestEntityTooLarge, "File too large", "Attachments may be at most "+strconv.Itoa(maxAttachmentSize>>20)+" MB")
+				return
+			}
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid upload", err.Error())
+			return
+		}
+		defer file.Close()
+
+		if header.Size > maxAttachmentSize {
+			helper.RespondWithError(c, http.StatusRequestEntityTooLarge, "File too large", "Attachments may be at most "+strconv.Itoa(maxAttachmentSize>>20)+" MB")
+			return
+		}
+
+		sniffed := make([]byte, 512)
+		n, err := io.ReadFull(file, sniffed)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid upload", err.Error())
+			return
+		}
+		sniffed = sniffed[:n]
+		contentType, _, _ := mime.ParseMediaType(http.DetectContentType(sniffed))
+		if !allowedAttachmentTypes[contentType] {
+			helper.RespondWithError(c, http.StatusUnsupportedMediaType, "Unsupported file type", "Files of type "+contentType+" cannot be attached")
+			return
+		}
+
+		name := filepath.Base(header.Filename)
+		if name == "." || name == string(filepath.Separator) {
+			name = "attachment"
+		}
+		if len(name) > 255 {
+			name = name[len(name)-255:]
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		task, err := findAccessibleTask(ctx, userID, taskID, model.RoleEditor)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching task")
+			return
+		}
+		if len(task.Attachments) >= maxTaskAttachments {
+			helper.RespondWithError(c, http.StatusConflict, "Too many attachments", "A task can have at most "+strconv.Itoa(maxTaskAttachments)+" attachments")
+			return
+		}
+
+		attachment := model.Attachment{
+			ID:          primitive.NewObjectID(),
+			Name:        name,
+			ContentType: contentType,
+			UploadedBy:  userID,
+			Created:     time.Now().UTC(),
+		}
+		attachment.Size, err = storage.Attachments.Put(ctx, attachment.ID.Hex(), io.MultiReader(bytes.NewReader(sniffed), file))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error storing attachment", err.Error())
+			return
+		}
+
+		// The size check in the filter keeps concurrent uploads from going over the limit
+		result, err := database.GetTaskCollection().UpdateOne(ctx,
+			helper.NotDeleted(helper.InWorkspace(ctx, bson.M{
+				"_id":     taskID,
+				"user_id": task.UserID,
+				"attachments." + strconv.Itoa(maxTaskAttachments-1): bson.M{"$exists": false},
+			})),
+			bson.M{"$push": bson.M{"attachments": attachment}},
+		)
+		if err == nil && result.MatchedCount == 0 {
+			err = helper.NewRequestError(http.StatusConflict, "Too many attachments", "A task can have at most "+strconv.Itoa(maxTaskAttachments)+" attachments")
+		}
+		if err != nil {
+			if deleteErr := storage.Attachments.Delete(ctx, attachment.ID.Hex()); deleteErr != nil {
+				log.Printf("Error removing attachment %s: %v", attachment.ID.Hex(), deleteErr)
+			}
+			helper.RespondWithRequestError(c, err, "Error saving attachment")
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusCreated, "Attachment added successfully for "+username, attachment)
+	}
+}
+
+// GetAttachment - Downloads an attachment of a task
+func GetAttachment() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID not found in context")
+			return
+		}
+
+		taskID, attachmentID, ok := parseAttachmentIDs(c)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		task, err := findAccessibleTask(ctx, userID, taskID, model.RoleViewer)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching task")
+			return
+		}
+		attachment, err := findAttachment(task, attachmentID)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching attachment")
+			return
+		}
+
+		// The download may outlast the lookup timeout, so it is bound to the request instead
+		blob, err := storage.Attachments.Open(c.Request.Context(), attachment.ID.Hex())
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				helper.RespondWithError(c, http.StatusNotFound, "Attachment not found", "The attachment's file is missing")
+				return
+			}
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error opening attachment", err.Error())
+			return
+		}
+		defer blob.Close()
+
+		disposition := mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name})
+		c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, blob, map[string]string{
+			"Content-Disposition":    disposition,
+			"X-Content-Type-Options": "nosniff",
+		})
+	}
+}
+
+// DeleteAttachment - Removes an attachment from a task and deletes its file
+func DeleteAttachment() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID not found in context")
+			return
+		}
+
+		taskID, attachmentID, ok := parseAttachmentIDs(c)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		task, err := findAccessibleTask(ctx, userID, taskID, model.RoleEditor)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching task")
+			return
+		}
+
+		result, err := database.GetTaskCollection().UpdateOne(ctx,
+			helper.NotDeleted(helper.InWorkspace(ctx, bson.M{"_id": taskID, "user_id": task.UserID, "attachments._id": attachmentID})),
+			bson.M{"$pull": bson.M{"attachments": bson.M{"_id": attachmentID}}},
+		)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error removing attachment", err.Error())
+			return
+		}
+		if result.MatchedCount == 0 {
+			helper.RespondWithError(c, http.StatusNotFound, "Attachment not found", "No attachment found for the specified ID and task")
+			return
+		}
+
+		if err := storage.Attachments.Delete(ctx, attachmentID.Hex()); err != nil {
+			log.Printf("Error deleting attachment %s: %v", attachmentID.Hex(), err)
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Attachment deleted successfully", nil)
+	}
+}
@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	database "task-manager/server/database"
+	helper "task-manager/server/helpers"
+	model "task-manager/server/models"
+)
+
+// maxBatchOperations - The most operations one batch may carry; the batch route's rate limit capacity matches it
+const maxBatchOperations = 100
+
+// batchOperation - One create, update or delete in a batch.
+// Task and Changes are decoded per operation so that one malformed entry does not reject the whole batch.
+type batchOperation struct {
+	Op       string          `json:"op"`
+	ID       string          `json:"id,omitempty"`
+	Task     json.RawMessage `json:"task,omitempty"`
+	Changes  json.RawMessage `json:"changes,omitempty"`
+	Children string          `json:"children,omitempty"`
+}
+
+// BatchResult is the outcome of one operation of a batch, in the same position as the operation
+type BatchResult struct {
+	Index   int         `json:"index"`
+	Op      string      `json:"op"`
+	Status  int         `json:"status"`
+	Task    *model.Task `json:"task,omitempty"`
+	Error   string      `json:"error,omitempty"`
+	Details string      `json:"details,omitempty"`
+}
+
+// runBatchOperation - Performs one operation of a batch, returning the task it created or updated
+func runBatchOperation(ctx context.Context, userID string, username string, op batchOperation) (*model.Task, int, error) {
+	switch op.Op {
+	case "create":
+		var newTask model.Task
+		if err := json.Unmarshal(op.Task, &newTask); err != nil {
+			return nil, 0, helper.NewRequestError(http.StatusBadRequest, "Invalid JSON input", err.Error())
+		}
+		task, err := createTask(ctx, userID, username, newTask)
+		return &task, http.StatusCreated, err
+
+	case "update", "delete":
+		id, err := primitive.ObjectIDFromHex(op.ID)
+		if err != nil {
+			return nil, 0, helper.NewRequestError(http.StatusBadRequest, "Invalid ID format", err.Error())
+		}
+
+		if op.Op == "delete" {
+			children := op.Children
+			if children == "" {
+				children = "delete"
+			}
+			_, err := deleteTask(ctx, userID, id, children)
+			return nil, http.StatusOK, err
+		}
+
+		var changes taskChanges
+		if err := json.Unmarshal(op.Changes, &changes); err != nil {
+			return nil, 0, helper.NewRequestError(http.StatusBadRequest, "Invalid JSON input", err.Error())
+		}
+		task, err := updateTask(ctx, userID, username, id, changes)
+		return &task, http.StatusOK, err
+	}
+
+	return nil, 0, helper.NewRequestError(http.StatusBadRequest, "Invalid operation", "op must be create, update or delete")
+}
+
+// runBatch - Runs every operation in order, recording a result for each.
+// When stopOnError is set the batch stops at the first failure and returns its error.
+func runBatch(ctx context.Context, userID string, username string, operations []batchOperation, stopOnError bool) ([]BatchResult, error) {
+	results := make([]BatchResult, len(operations))
+	for i, op := range operations {
+		results[i] = BatchResult{Index: i, Op: op.Op}
+	}
+
+	for i, op := range operations {
+		task, status, err := runBatchOperation(ctx, userID, username, op)
+		if err != nil {
+			var reqErr *helper.RequestError
+			if errors.As(err, &reqErr) {
+				results[i].Status, results[i].Error, results[i].Details = reqErr.Code, reqErr.Message, reqErr.Details
+			} else {
+				results[i].Status, results[i].Error, results[i].Details = http.StatusInternalServerError, "Error running operation", err.Error()
+			}
+			if stopOnError {
+				return results, err
+			}
+			continue
+		}
+
+		results[i].Status = status
+		results[i].Task = task
+	}
+	return results, nil
+}
+
+// describeBatchFailure - Names the operation that aborted an atomic batch and why it failed
+func describeBatchFailure(results []BatchResult) string {
+	for _, result := range results {
+		if result.Error != "" {
+			return fmt.Sprintf("Operation %d (%s) failed: %s: %s", result.Index, result.Op, result.Error, result.Details)
+		}
+	}
+	return "An operation in the batch failed"
+}
+
+// BatchTasks - Creates, updates and deletes several tasks in one request.
+// Each operation gets its own result. With "atomic": true the batch runs in a transaction and is
+// rolled back entirely if any operation fails, which is reported as an error naming the failed operation;
+// otherwise operations succeed or fail independently.
+func BatchTasks() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		var request struct {
+			Atomic     bool             `json:"atomic"`
+			Operations []batchOperation `json:"operations"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid JSON input", err.Error())
+			return
+		}
+		if len(request.Operations) == 0 || len(request.Operations) > maxBatchOperations {
+			helper.RespondWithError(c, http.StatusBadRequest, "Validation error", "operations must hold between 1 and "+strconv.Itoa(maxBatchOperations)+" entries")
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		if !request.Atomic {
+			results, _ := runBatch(ctx, userID, username, request.Operations, false)
+			helper.RespondWithSuccess(c, http.StatusOK, "Batch processed for "+username, results)
+			return
+		}
+
+		var results []BatchResult
+		err := database.WithTransaction(ctx, func(txCtx context.Context) error {
+			var err error
+			results, err = runBatch(txCtx, userID, username, request.Operations, true)
+			return err
+		})
+		if err != nil {
+			var reqErr *helper.RequestError
+			if errors.As(err, &reqErr) {
+				helper.RespondWithError(c, reqErr.Code, "Batch rolled back, no changes were made", describeBatchFailure(results))
+				return
+			}
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error running batch", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Batch committed for "+username, results)
+	}
+}
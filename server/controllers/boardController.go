@@ -0,0 +1,275 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	database "task-manager/server/database"
+	helper "task-manager/server/helpers"
+	model "task-manager/server/models"
+)
+
+// maxBoardColumnTasks - How many tasks the board view returns per column; the count covers them all
+const maxBoardColumnTasks = 200
+
+// findBoard - Loads the board of one of the user's projects
+func findBoard(ctx context.Context, userID string, projectID primitive.ObjectID) (model.Board, error) {
+	var board model.Board
+	// Boards are kept per user, so the project is what ties them to the workspace
+	if _, err := findProject(ctx, userID, projectID); err != nil {
+		return board, err
+	}
+	err := database.GetBoardCollection().FindOne(ctx, bson.M{"user_id": userID, "project_id": projectID}).Decode(&board)
+	if err == mongo.ErrNoDocuments {
+		return board, helper.NewRequestError(http.StatusNotFound, "Board not found", "The project has no board")
+	}
+	return board, err
+}
+
+// checkBoardColumns - Gives new columns an ID and ensures no status is claimed by two columns
+func checkBoardColumns(columns []model.BoardColumn) error {
+	seen := map[model.TaskStatus]string{}
+	for i := range columns {
+		if columns[i].ID.IsZero() {
+			columns[i].ID = primitive.NewObjectID()
+		}
+		for _, status := range columns[i].Statuses {
+			if other, ok := seen[status]; ok {
+				return helper.NewRequestError(http.StatusBadRequest, "Invalid board columns", "Status "+string(status)+" is already in column "+other)
+			}
+			seen[status] = columns[i].Name
+		}
+	}
+	return nil
+}
+
+// columnTaskFilter - Matches the project's tasks that sit in the column
+func columnTaskFilter(ctx context.Context, userID string, projectID primitive.ObjectID, column model.BoardColumn) bson.M {
+	return helper.NotDeleted(helper.InWorkspace(ctx, bson.M{"user_id": userID, "project_id": projectID, "status": bson.M{"$in": column.Statuses}}))
+}
+
+// checkWIPLimit - Rejects moving a task into a board column that is already at its WIP limit.
+// Moves within a column, into a project without a board or into a column without a limit are always allowed.
+func checkWIPLimit(ctx context.Context, userID string, current model.Task, projectID *primitive.ObjectID, status model.TaskStatus) error {
+	if projectID == nil {
+		return nil
+	}
+
+	board, err := findBoard(ctx, userID, *projectID)
+	var reqErr *helper.RequestError
+	if errors.As(err, &reqErr) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	column := board.ColumnFor(status)
+	if column == nil || column.WIPLimit == 0 {
+		return nil
+	}
+	if sameObjectID(current.ProjectID, projectID) && board.ColumnFor(current.Status) == column {
+		return nil
+	}
+
+	count, err := database.GetTaskCollection().CountDocuments(ctx, columnTaskFilter(ctx, userID, *projectID, *column))
+	if err != nil {
+		return err
+	}
+	if count >= int64(column.WIPLimit) {
+		return helper.NewRequestError(http.StatusConflict, "Column is full", "Column "+column.Name+" is at its WIP limit of "+strconv.Itoa(column.WIPLimit))
+	}
+	return nil
+}
+
+// GetBoard - Retrieves the board of a project
+func GetBoard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		projectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		board, err := findBoard(ctx, userID, projectID)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching board")
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Board for "+username, board)
+	}
+}
+
+// PutBoard - Creates the board of a project, or replaces its name and columns
+func PutBoard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		projectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		var board model.Board
+		if err := c.ShouldBindJSON(&board); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid JSON input", err.Error())
+			return
+		}
+		board.UserID = userID
+		board.ProjectID = projectID
+
+		if err := validate.Struct(board); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Validation error", err.Error())
+			return
+		}
+		if err := checkBoardColumns(board.Columns); err != nil {
+			helper.RespondWithRequestError(c, err, "Error checking board columns")
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		project, err := findProject(ctx, userID, projectID)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching project")
+			return
+		}
+		if project.IsFolder {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid project", "Folders cannot have a board")
+			return
+		}
+
+		now := time.Now().UTC()
+		opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+		err = database.GetBoardCollection().FindOneAndUpdate(ctx,
+			bson.M{"user_id": userID, "project_id": projectID},
+			bson.M{
+				"$set":         bson.M{"name": board.Name, "columns": board.Columns, "updated_at": now},
+				"$setOnInsert": bson.M{"created_at": now},
+			},
+			opts,
+		).Decode(&board)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error saving board", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Board saved successfully for "+username, board)
+	}
+}
+
+// DeleteBoard - Removes the board of a project. Its tasks are not affected.
+func DeleteBoard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID not found in context")
+			return
+		}
+
+		projectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		result, err := database.GetBoardCollection().DeleteOne(ctx, bson.M{"user_id": userID, "project_id": projectID})
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error deleting board", err.Error())
+			return
+		}
+		if result.DeletedCount == 0 {
+			helper.RespondWithError(c, http.StatusNotFound, "Board not found", "The project has no board")
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Board deleted successfully", nil)
+	}
+}
+
+// GetBoardTasks - Retrieves a project's board with its tasks grouped by column, each column in manual order.
+// Every column carries its full task count and whether it has reached its WIP limit.
+func GetBoardTasks() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		projectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		board, err := findBoard(ctx, userID, projectID)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching board")
+			return
+		}
+
+		view := model.BoardView{Board: board, Columns: make([]model.BoardColumnView, len(board.Columns))}
+		collection := database.GetTaskCollection()
+		opts := options.Find().
+			SetSort(bson.D{{Key: "rank", Value: 1}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}).
+			SetLimit(maxBoardColumnTasks)
+
+		for i, column := range board.Columns {
+			filter := columnTaskFilter(ctx, userID, projectID, column)
+			columnView := model.BoardColumnView{BoardColumn: column, Tasks: []model.Task{}}
+
+			if columnView.Count, err = collection.CountDocuments(ctx, filter); err != nil {
+				helper.RespondWithError(c, http.StatusInternalServerError, "Error counting tasks", err.Error())
+				return
+			}
+			columnView.Full = column.WIPLimit > 0 && columnView.Count >= int64(column.WIPLimit)
+
+			cursor, err := collection.Find(ctx, filter, opts)
+			if err != nil {
+				helper.RespondWithError(c, http.StatusInternalServerError, "Error fetching tasks", err.Error())
+				return
+			}
+			err = cursor.All(ctx, &columnView.Tasks)
+			cursor.Close(ctx)
+			if err != nil {
+				helper.RespondWithError(c, http.StatusInternalServerError, "Error decoding tasks", err.Error())
+				return
+			}
+
+			view.Columns[i] = columnView
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Board for "+username, view)
+	}
+}
@@ -0,0 +1,309 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	database "task-manager/server/database"
+	helper "task-manager/server/helpers"
+	model "task-manager/server/models"
+)
+
+// normalizeChecklist - Gives new items an ID and renumbers positions to match the slice order
+func normalizeChecklist(items []model.ChecklistItem) []model.ChecklistItem {
+	for i := range items {
+		if items[i].ID.IsZero() {
+			items[i].ID = primitive.NewObjectID()
+		}
+		items[i].Position = i
+	}
+	return items
+}
+
+// checklistItemIndex - Finds an item in the checklist, returning -1 if it is missing
+func checklistItemIndex(items []model.ChecklistItem, itemID primitive.ObjectID) int {
+	for i, item := range items {
+		if item.ID == itemID {
+			return i
+		}
+	}
+	return -1
+}
+
+// autoCompleteChecklist - Adds marking the task done to a checklist update, with the same checks as any other
+// status change. A task that cannot be done yet, because it waits for open prerequisites or its board column
+// is full, keeps its status and only the checklist is saved.
+func autoCompleteChecklist(ctx context.Context, current model.Task, now time.Time, update, unset bson.M) error {
+	statusUpdate, statusUnset := bson.M{}, bson.M{}
+	err := applyStatusChange(current, model.StatusDone, now, statusUpdate, statusUnset)
+	if err == nil {
+		err = checkBlockedStatus(ctx, current, model.StatusDone, statusUpdate, statusUnset)
+	}
+	if err == nil {
+		err = checkWIPLimit(ctx, current.UserID, current, current.ProjectID, model.StatusDone)
+	}
+	var reqErr *helper.RequestError
+	if errors.As(err, &reqErr) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for field, value := range statusUpdate {
+		update[field] = value
+	}
+	for field := range statusUnset {
+		unset[field] = ""
+	}
+	return nil
+}
+
+// updateChecklist - Loads the task, lets change rewrite its checklist and saves the result.
+// The write only succeeds if the task is unchanged since it was read, so concurrent edits are not lost.
+// When every item ends up checked and the task opted into auto-completion, the task is marked done.
+func updateChecklist(ctx context.Context, userID string, username string, taskID primitive.ObjectID, change func([]model.ChecklistItem) ([]model.ChecklistItem, error)) (model.Task, error) {
+	collection := database.GetTaskCollection()
+	filter := helper.NotDeleted(helper.InWorkspace(ctx, bson.M{"_id": taskID, "user_id": userID}))
+
+	var current model.Task
+	if err := collection.FindOne(ctx, filter).Decode(&current); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return current, helper.NewRequestError(http.StatusNotFound, "Task not found", "No task found for the specified ID and user")
+		}
+		return current, err
+	}
+
+	items, err := change(append([]model.ChecklistItem{}, current.Checklist...))
+	if err != nil {
+		return current, err
+	}
+	items = normalizeChecklist(items)
+
+	if err := validate.Var(items, "max=100,dive"); err != nil {
+		return current, helper.NewRequestError(http.StatusBadRequest, "Validation error", err.Error())
+	}
+
+	now := time.Now().UTC()
+	update := bson.M{"checklist": items, "updated_at": now}
+	unset := bson.M{}
+
+	progress := model.Task{Checklist: items}.ChecklistProgress()
+	allDone := progress != nil && progress.Done == progress.Total
+	if allDone && current.ChecklistAutoComplete && current.Status.CanTransitionTo(model.StatusDone) && current.Status != model.StatusDone {
+		if err := autoCompleteChecklist(ctx, current, now, update, unset); err != nil {
+			return current, err
+		}
+	}
+
+	changes := bson.M{"$set": update}
+	if len(unset) > 0 {
+		changes["$unset"] = unset
+	}
+
+	filter["updated_at"] = current.Updated
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var task model.Task
+	if err := collection.FindOneAndUpdate(ctx, filter, changes, opts).Decode(&task); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return current, helper.NewRequestError(http.StatusConflict, "Task was modified concurrently", "The task changed while updating its checklist, please retry")
+		}
+		return current, err
+	}
+	logRevision(ctx, userID, username, model.RevisionUpdate, &current, task)
+	logOperation(ctx, model.Operation{UserID: userID, OwnerID: userID, Action: model.OperationUpdate, TaskID: &task.ID, Before: &current, After: &task, Stamp: task.Updated})
+
+	if err := afterStatusChange(ctx, userID, task, current.Status, now); err != nil {
+		return task, err
+	}
+	return task, nil
+}
+
+// AddChecklistItem - Appends an item to the end of a task's checklist
+func AddChecklistItem() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		taskID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		var newItem model.ChecklistItem
+		if err := c.ShouldBindJSON(&newItem); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid JSON input", err.Error())
+			return
+		}
+		newItem.ID = primitive.NewObjectID()
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		task, err := updateChecklist(ctx, userID, username, taskID, func(items []model.ChecklistItem) ([]model.ChecklistItem, error) {
+			return append(items, newItem), nil
+		})
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error adding checklist item")
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusCreated, "Checklist item added successfully", task)
+	}
+}
+
+// UpdateChecklistItem - Edits the text of a checklist item or checks/unchecks it
+func UpdateChecklistItem() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		taskID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+		itemID, err := primitive.ObjectIDFromHex(c.Param("item_id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid item ID format", err.Error())
+			return
+		}
+
+		var updatedFields struct {
+			Text *string `json:"text"`
+			Done *bool   `json:"done"`
+		}
+		if err := c.ShouldBindJSON(&updatedFields); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid JSON input", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		task, err := updateChecklist(ctx, userID, username, taskID, func(items []model.ChecklistItem) ([]model.ChecklistItem, error) {
+			i := checklistItemIndex(items, itemID)
+			if i < 0 {
+				return nil, helper.NewRequestError(http.StatusNotFound, "Checklist item not found", "No item found for the specified ID")
+			}
+			if updatedFields.Text != nil {
+				items[i].Text = *updatedFields.Text
+			}
+			if updatedFields.Done != nil {
+				items[i].Done = *updatedFields.Done
+			}
+			return items, nil
+		})
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error updating checklist item")
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Checklist item updated successfully", task)
+	}
+}
+
+// ReorderChecklist - Puts the checklist in the order of the given item IDs, which must list every item once
+func ReorderChecklist() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		taskID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		var request struct {
+			ItemIDs []primitive.ObjectID `json:"item_ids"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid JSON input", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		task, err := updateChecklist(ctx, userID, username, taskID, func(items []model.ChecklistItem) ([]model.ChecklistItem, error) {
+			if len(request.ItemIDs) != len(items) {
+				return nil, helper.NewRequestError(http.StatusBadRequest, "Invalid checklist order", "item_ids must list every checklist item exactly once")
+			}
+
+			reordered := make([]model.ChecklistItem, 0, len(items))
+			seen := map[primitive.ObjectID]bool{}
+			for _, itemID := range request.ItemIDs {
+				i := checklistItemIndex(items, itemID)
+				if i < 0 || seen[itemID] {
+					return nil, helper.NewRequestError(http.StatusBadRequest, "Invalid checklist order", "item_ids must list every checklist item exactly once")
+				}
+				seen[itemID] = true
+				reordered = append(reordered, items[i])
+			}
+			return reordered, nil
+		})
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error reordering checklist")
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Checklist reordered successfully", task)
+	}
+}
+
+// DeleteChecklistItem - Removes an item from a task's checklist
+func DeleteChecklistItem() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		taskID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+		itemID, err := primitive.ObjectIDFromHex(c.Param("item_id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid item ID format", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		task, err := updateChecklist(ctx, userID, username, taskID, func(items []model.ChecklistItem) ([]model.ChecklistItem, error) {
+			i := checklistItemIndex(items, itemID)
+			if i < 0 {
+				return nil, helper.NewRequestError(http.StatusNotFound, "Checklist item not found", "No item found for the specified ID")
+			}
+			return append(items[:i], items[i+1:]...), nil
+		})
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error removing checklist item")
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Checklist item removed successfully", task)
+	}
+}
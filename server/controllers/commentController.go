@@ -0,0 +1,338 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	database "task-manager/server/database"
+	helper "task-manager/server/helpers"
+	model "task-manager/server/models"
+)
+
+// maxCommentEdits - How many earlier versions of a comment are kept; older ones are dropped
+const maxCommentEdits = 50
+
+// commentRequest - The body of a comment being posted or edited
+type commentRequest struct {
+	Body string `json:"body" validate:"required,min=1,max=10000"`
+}
+
+// bindComment - Reads and validates a comment body from the request, ignoring surrounding whitespace
+func bindComment(c *gin.Context) (string, bool) {
+	var request commentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		helper.RespondWithError(c, http.StatusBadRequest, "Invalid JSON input", err.Error())
+		return "", false
+	}
+	request.Body = strings.TrimSpace(request.Body)
+	if err := validate.Struct(request); err != nil {
+		helper.RespondWithError(c, http.StatusBadRequest, "Validation error", err.Error())
+		return "", false
+	}
+	return request.Body, true
+}
+
+// parseCommentIDs - Reads the task and comment IDs from the path
+func parseCommentIDs(c *gin.Context) (primitive.ObjectID, primitive.ObjectID, bool) {
+	taskID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+		return taskID, taskID, false
+	}
+	commentID, err := primitive.ObjectIDFromHex(c.Param("comment_id"))
+	if err != nil {
+		helper.RespondWithError(c, http.StatusBadRequest, "Invalid comment ID format", err.Error())
+		return taskID, commentID, false
+	}
+	return taskID, commentID, true
+}
+
+// findComment - Loads a comment of a task
+func findComment(ctx context.Context, taskID primitive.ObjectID, id primitive.ObjectID) (model.Comment, error) {
+	var comment model.Comment
+	err := database.GetCommentCollection().FindOne(ctx, bson.M{"_id": id, "task_id": taskID}).Decode(&comment)
+	if err == mongo.ErrNoDocuments {
+		return comment, helper.NewRequestError(http.StatusNotFound, "Comment not found", "No comment found for the specified ID and task")
+	}
+	return comment, err
+}
+
+// countComment - Keeps the task's denormalised comment count in step, so task listings need no extra query
+func countComment(ctx context.Context, taskID primitive.ObjectID, delta int) error {
+	_, err := database.GetTaskCollection().UpdateOne(ctx, helper.InWorkspace(ctx, bson.M{"_id": taskID}), bson.M{"$inc": bson.M{"comment_count": delta}})
+	return err
+}
+
+// GetComments - Retrieves a page of a task's comments, oldest first, without their edit history.
+// The next page is fetched with ?cursor= set to the returned next_cursor.
+func GetComments() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		taskID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		limit, err := parsePageSize(c)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+			return
+		}
+
+		filter := bson.M{"task_id": taskID}
+		if cursor := c.Query("cursor"); cursor != "" {
+			after, err := primitive.ObjectIDFromHex(cursor)
+			if err != nil {
+				helper.RespondWithError(c, http.StatusBadRequest, "Invalid cursor", "The cursor is not valid")
+				return
+			}
+			filter["_id"] = bson.M{"$gt": after}
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		if _, err := findAccessibleTask(ctx, userID, taskID, model.RoleViewer); err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching task")
+			return
+		}
+
+		// One extra comment tells whether there is a next page
+		opts := options.Find().
+			SetSort(bson.D{{Key: "_id", Value: 1}}).
+			SetLimit(int64(limit + 1)).
+			SetProjection(bson.M{"edits": 0})
+		cursor, err := database.GetCommentCollection().Find(ctx, filter, opts)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error fetching comments", err.Error())
+			return
+		}
+		defer cursor.Close(ctx)
+
+		page := model.CommentPage{Comments: []model.Comment{}}
+		if err := cursor.All(ctx, &page.Comments); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error decoding comments", err.Error())
+			return
+		}
+		if len(page.Comments) > limit {
+			page.Comments = page.Comments[:limit]
+			page.NextCursor = page.Comments[limit-1].ID.Hex()
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Comments for "+username, page)
+	}
+}
+
+// GetComment - Retrieves a single comment with its edit history
+func GetComment() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		taskID, commentID, ok := parseCommentIDs(c)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		if _, err := findAccessibleTask(ctx, userID, taskID, model.RoleViewer); err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching task")
+			return
+		}
+		comment, err := findComment(ctx, taskID, commentID)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching comment")
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Comment for "+username, comment)
+	}
+}
+
+// PostComment - Adds a comment to a task. Anyone the task is shared with may comment on it.
+func PostComment() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		taskID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		body, ok := bindComment(c)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		task, err := findAccessibleTask(ctx, userID, taskID, model.RoleViewer)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching task")
+			return
+		}
+
+		comment := model.Comment{
+			ID:       primitive.NewObjectID(),
+			TaskID:   taskID,
+			UserID:   task.UserID,
+			AuthorID: userID,
+			Author:   username,
+			Body:     body,
+			Created:  time.Now().UTC(),
+		}
+		if _, err := database.GetCommentCollection().InsertOne(ctx, comment); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error inserting comment", err.Error())
+			return
+		}
+		if err := countComment(ctx, taskID, 1); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error counting comments", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusCreated, "Comment added successfully", comment)
+	}
+}
+
+// UpdateComment - Replaces the body of a comment, keeping the previous body in its edit history.
+// Only the comment's author may edit it.
+func UpdateComment() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		taskID, commentID, ok := parseCommentIDs(c)
+		if !ok {
+			return
+		}
+
+		body, ok := bindComment(c)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		if _, err := findAccessibleTask(ctx, userID, taskID, model.RoleViewer); err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching task")
+			return
+		}
+		comment, err := findComment(ctx, taskID, commentID)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching comment")
+			return
+		}
+		if comment.AuthorID != userID {
+			helper.RespondWithError(c, http.StatusForbidden, "Not allowed", "Only the author of a comment can edit it")
+			return
+		}
+		if comment.Body == body {
+			helper.RespondWithSuccess(c, http.StatusOK, "Comment unchanged for "+username, comment)
+			return
+		}
+
+		// Matching on the old body keeps two concurrent edits from losing one another
+		now := time.Now().UTC()
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		err = database.GetCommentCollection().FindOneAndUpdate(ctx,
+			bson.M{"_id": commentID, "task_id": taskID, "body": comment.Body},
+			bson.M{
+				"$set":  bson.M{"body": body, "updated_at": now},
+				"$push": bson.M{"edits": bson.M{"$each": bson.A{model.CommentEdit{Body: comment.Body, Edited: now}}, "$slice": -maxCommentEdits}},
+			},
+			opts,
+		).Decode(&comment)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				helper.RespondWithError(c, http.StatusConflict, "Comment was modified concurrently", "The comment changed while editing it, please retry")
+				return
+			}
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error updating comment", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Comment updated successfully for "+username, comment)
+	}
+}
+
+// DeleteComment - Removes a comment. Its author and the owners of the task may delete it.
+func DeleteComment() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID not found in context")
+			return
+		}
+
+		taskID, commentID, ok := parseCommentIDs(c)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		task, err := findAccessibleTask(ctx, userID, taskID, model.RoleViewer)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching task")
+			return
+		}
+		comment, err := findComment(ctx, taskID, commentID)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching comment")
+			return
+		}
+		role, err := taskRole(ctx, userID, task)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error fetching shares", err.Error())
+			return
+		}
+		if comment.AuthorID != userID && role != model.RoleOwner {
+			helper.RespondWithError(c, http.StatusForbidden, "Not allowed", "Only the author of a comment or an owner of the task can delete it")
+			return
+		}
+
+		result, err := database.GetCommentCollection().DeleteOne(ctx, bson.M{"_id": commentID, "task_id": taskID})
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error deleting comment", err.Error())
+			return
+		}
+		if result.DeletedCount > 0 {
+			if err := countComment(ctx, taskID, -1); err != nil {
+				helper.RespondWithError(c, http.StatusInternalServerError, "Error counting comments", err.Error())
+				return
+			}
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Comment deleted successfully", nil)
+	}
+}
@@ -0,0 +1,479 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	database "task-manager/server/database"
+	helper "task-manager/server/helpers"
+	model "task-manager/server/models"
+)
+
+// maxTaskDependencies - How many tasks one task can be blocked by
+const maxTaskDependencies = 50
+
+// openPrerequisites - Returns which of the given tasks are still open, leaving out the trash
+func openPrerequisites(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]bool, error) {
+	open := map[primitive.ObjectID]bool{}
+	if len(ids) == 0 {
+		return open, nil
+	}
+
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+	filter := helper.NotDeleted(helper.InWorkspace(ctx, bson.M{"_id": bson.M{"$in": ids}, "status": bson.M{"$nin": model.ClosedStatuses}}))
+	cursor, err := database.GetTaskCollection().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var tasks []model.Task
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return nil, err
+	}
+	for _, task := range tasks {
+		open[task.ID] = true
+	}
+	return open, nil
+}
+
+// syncBlockedStatus - Keeps the tasks matched by filter blocked while any of their prerequisites are open.
+// Open tasks waiting on a prerequisite are moved to blocked, remembering the status they resume once all of
+// their prerequisites are closed. Tasks blocked by hand stay blocked until someone moves them.
+func syncBlockedStatus(ctx context.Context, filter bson.M) error {
+	collection := database.GetTaskCollection()
+	opts := options.Find().SetProjection(bson.M{"_id": 1, "status": 1, "blocked_by": 1, "resume_status": 1})
+	cursor, err := collection.Find(ctx, helper.NotDeleted(helper.InWorkspace(ctx, filter)), opts)
+	if err != nil {
+		return err
+	}
+	var tasks []model.Task
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return err
+	}
+
+	var prerequisites []primitive.ObjectID
+	for _, task := range tasks {
+		prerequisites = append(prerequisites, task.BlockedBy...)
+	}
+	open, err := openPrerequisites(ctx, prerequisites)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	for _, task := range tasks {
+		waiting := false
+		for _, id := range task.BlockedBy {
+			waiting = waiting || open[id]
+		}
+
+		var change bson.M
+		switch {
+		case waiting && (task.Status == model.StatusTodo || task.Status == model.StatusInProgress):
+			change = bson.M{"$set": bson.M{"status": model.StatusBlocked, "resume_status": task.Status, "updated_at": now}}
+		case !waiting && task.Status == model.StatusBlocked && task.ResumeStatus != "":
+			change = bson.M{"$set": bson.M{"status": task.ResumeStatus, "updated_at": now}, "$unset": bson.M{"resume_status": ""}}
+		default:
+			continue
+		}
+
+		// Matching on the status leaves alone tasks that were moved in the meantime
+		if _, err := collection.UpdateOne(ctx, helper.InWorkspace(ctx, bson.M{"_id": task.ID, "status": task.Status}), change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncDependents - Re-evaluates the blocked status of the tasks waiting on any of the given tasks,
+// after they were closed, reopened, trashed or restored
+func syncDependents(ctx context.Context, ids []primitive.ObjectID) error {
+	return syncBlockedStatus(ctx, bson.M{"blocked_by": bson.M{"$in": ids}})
+}
+
+// checkBlockedStatus - Keeps a task with open prerequisites from being started or finished, and blocks it again
+// straight away when it is moved back to todo. A status picked by hand replaces one set automatically.
+func checkBlockedStatus(ctx context.Context, current model.Task, next model.TaskStatus, update, unset bson.M) error {
+	if current.ResumeStatus != "" {
+		unset["resume_status"] = ""
+	}
+	if next == model.StatusBlocked || next == model.StatusCancelled || len(current.BlockedBy) == 0 {
+		return nil
+	}
+
+	open, err := openPrerequisites(ctx, current.BlockedBy)
+	if err != nil || len(open) == 0 {
+		return err
+	}
+	if next != model.StatusTodo {
+		return helper.NewRequestError(http.StatusConflict, "Task is blocked", fmt.Sprintf("%d of the tasks it is blocked by are still open", len(open)))
+	}
+	update["status"] = model.StatusBlocked
+	update["resume_status"] = model.StatusTodo
+	delete(unset, "resume_status")
+	return nil
+}
+
+// checkDependencyCycle - Ensures making the task wait for the prerequisite does not close a loop, which it would
+// if the prerequisite already waits for the task, directly or through other tasks. Trashed tasks are followed
+// too, so restoring them cannot bring a loop back.
+func checkDependencyCycle(ctx context.Context, taskID primitive.ObjectID, prerequisiteID primitive.ObjectID) error {
+	if taskID == prerequisiteID {
+		return helper.NewRequestError(http.StatusBadRequest, "Invalid dependency", "A task cannot be blocked by itself")
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: helper.InWorkspace(ctx, bson.M{"_id": prerequisiteID})}},
+		{{Key: "$graphLookup", Value: bson.M{
+			"from":                    "tasks",
+			"startWith":               "$blocked_by",
+			"connectFromField":        "blocked_by",
+			"connectToField":          "_id",
+			"as":                      "prerequisites",
+			"restrictSearchWithMatch": helper.InWorkspace(ctx, bson.M{}),
+		}}},
+		{{Key: "$project", Value: bson.M{"cycle": bson.M{"$in": bson.A{taskID, "$prerequisites._id"}}}}},
+	}
+
+	cursor, err := database.GetTaskCollection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	var results []struct {
+		Cycle bool `bson:"cycle"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return err
+	}
+	if len(results) > 0 && results[0].Cycle {
+		return helper.NewRequestError(http.StatusConflict, "Dependency cycle", "The prerequisite is already blocked by this task, directly or through other tasks")
+	}
+	return nil
+}
+
+// linkDependency - Makes the task wait for the prerequisite unless that closes a loop. Matching on updated_at
+// keeps the limit from being exceeded by concurrent links, and bumping the prerequisite's makes a concurrent
+// link in the other direction conflict with this one.
+func linkDependency(ctx context.Context, task model.Task, prerequisite model.Task) error {
+	if err := checkDependencyCycle(ctx, task.ID, prerequisite.ID); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	collection := database.GetTaskCollection()
+	result, err := collection.UpdateOne(ctx,
+		helper.NotDeleted(helper.InWorkspace(ctx, bson.M{"_id": task.ID, "updated_at": task.Updated})),
+		bson.M{
+			"$addToSet": bson.M{"blocked_by": prerequisite.ID},
+			"$set":      bson.M{"updated_at": now},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("adding dependency: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return helper.NewRequestError(http.StatusConflict, "Task was modified concurrently", "The task changed while adding the dependency, please retry")
+	}
+
+	result, err = collection.UpdateOne(ctx,
+		helper.NotDeleted(helper.InWorkspace(ctx, bson.M{"_id": prerequisite.ID, "updated_at": prerequisite.Updated})),
+		bson.M{"$set": bson.M{"updated_at": now}},
+	)
+	if err != nil {
+		return fmt.Errorf("claiming prerequisite: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return helper.NewRequestError(http.StatusConflict, "Task was modified concurrently", "The prerequisite changed while adding the dependency, please retry")
+	}
+	return nil
+}
+
+// findPrerequisite - Loads a task the user wants another task to wait for, which they must be able to see
+func findPrerequisite(ctx context.Context, userID string, id primitive.ObjectID) (model.Task, error) {
+	task, err := findAccessibleTask(ctx, userID, id, model.RoleViewer)
+	var reqErr *helper.RequestError
+	if errors.As(err, &reqErr) {
+		return task, helper.NewRequestError(http.StatusBadRequest, "Invalid dependency", "Prerequisite task not found")
+	}
+	return task, err
+}
+
+// findVisibleTasks - Loads the tasks matched by filter that the user can see, in rank order, leaving out the trash
+func findVisibleTasks(ctx context.Context, userID string, filter bson.M) ([]model.Task, error) {
+	access, err := accessibleTaskFilter(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "rank", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := database.GetTaskCollection().Find(ctx, helper.NotDeleted(bson.M{"$and": bson.A{access, filter}}), opts)
+	if err != nil {
+		return nil, err
+	}
+	tasks := []model.Task{}
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// sortByDependencies - Orders tasks so that every task comes after the prerequisites it has among them, starting
+// from rank order, and returns the links between them. Tasks caught in a cycle, which only concurrent links can
+// create, are added at the end.
+func sortByDependencies(tasks []model.Task) ([]model.Task, []model.DependencyEdge) {
+	index := make(map[primitive.ObjectID]int, len(tasks))
+	for i, task := range tasks {
+		index[task.ID] = i
+	}
+
+	waiting := make([]int, len(tasks))
+	dependents := make([][]int, len(tasks))
+	edges := []model.DependencyEdge{}
+	for i, task := range tasks {
+		for _, id := range task.BlockedBy {
+			if j, ok := index[id]; ok {
+				waiting[i]++
+				dependents[j] = append(dependents[j], i)
+				edges = append(edges, model.DependencyEdge{From: id, To: task.ID})
+			}
+		}
+	}
+
+	var ready []int
+	for i := range tasks {
+		if waiting[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	order := make([]model.Task, 0, len(tasks))
+	for len(ready) > 0 {
+		i := ready[0]
+		ready = ready[1:]
+		order = append(order, tasks[i])
+		for _, j := range dependents[i] {
+			if waiting[j]--; waiting[j] == 0 {
+				ready = append(ready, j)
+			}
+		}
+	}
+	for i, task := range tasks {
+		if waiting[i] > 0 {
+			order = append(order, task)
+		}
+	}
+	return order, edges
+}
+
+// GetDependencies - Lists the tasks a task is blocked by and the tasks it blocks
+func GetDependencies() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		task, err := findAccessibleTask(ctx, userID, id, model.RoleViewer)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching task")
+			return
+		}
+
+		dependencies := model.Dependencies{BlockedBy: []model.Task{}}
+		if len(task.BlockedBy) > 0 {
+			if dependencies.BlockedBy, err = findVisibleTasks(ctx, userID, bson.M{"_id": bson.M{"$in": task.BlockedBy}}); err != nil {
+				helper.RespondWithError(c, http.StatusInternalServerError, "Error fetching dependencies", err.Error())
+				return
+			}
+		}
+		if dependencies.Blocks, err = findVisibleTasks(ctx, userID, bson.M{"blocked_by": id}); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error fetching dependencies", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Dependencies of task for "+username, dependencies)
+	}
+}
+
+// AddDependency - Makes a task wait for another task of the workspace, given as blocked_by.
+// The task is blocked until that prerequisite is done or cancelled.
+func AddDependency() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		var request struct {
+			BlockedBy primitive.ObjectID `json:"blocked_by"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid JSON input", err.Error())
+			return
+		}
+		if request.BlockedBy.IsZero() {
+			helper.RespondWithError(c, http.StatusBadRequest, "Validation error", "blocked_by is required")
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		task, err := findAccessibleTask(ctx, userID, id, model.RoleEditor)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching task")
+			return
+		}
+		if task.IsBlockedBy(request.BlockedBy) {
+			helper.RespondWithSuccess(c, http.StatusOK, "Task is already blocked by it for "+username, task)
+			return
+		}
+		if len(task.BlockedBy) >= maxTaskDependencies {
+			helper.RespondWithError(c, http.StatusBadRequest, "Validation error", fmt.Sprintf("A task can be blocked by at most %d tasks", maxTaskDependencies))
+			return
+		}
+		prerequisite, err := findPrerequisite(ctx, userID, request.BlockedBy)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching prerequisite")
+			return
+		}
+
+		// Checking for a cycle in the same transaction as the link keeps concurrent links from closing one
+		err = database.WithTransaction(ctx, func(txCtx context.Context) error {
+			return linkDependency(txCtx, task, prerequisite)
+		})
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error adding dependency")
+			return
+		}
+		if err := syncBlockedStatus(ctx, bson.M{"_id": id}); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error blocking task", err.Error())
+			return
+		}
+
+		if task, err = findAccessibleTask(ctx, userID, id, model.RoleViewer); err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching task")
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Dependency added successfully for "+username, task)
+	}
+}
+
+// RemoveDependency - Stops a task waiting for one of its prerequisites, unblocking it when no other open ones remain
+func RemoveDependency() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+		prerequisiteID, err := primitive.ObjectIDFromHex(c.Param("blocker_id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		task, err := findAccessibleTask(ctx, userID, id, model.RoleEditor)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching task")
+			return
+		}
+		if !task.IsBlockedBy(prerequisiteID) {
+			helper.RespondWithError(c, http.StatusNotFound, "Dependency not found", "The task is not blocked by the specified task")
+			return
+		}
+
+		_, err = database.GetTaskCollection().UpdateOne(ctx,
+			helper.NotDeleted(helper.InWorkspace(ctx, bson.M{"_id": id})),
+			bson.M{
+				"$pull": bson.M{"blocked_by": prerequisiteID},
+				"$set":  bson.M{"updated_at": time.Now().UTC()},
+			},
+		)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error removing dependency", err.Error())
+			return
+		}
+		if err := syncBlockedStatus(ctx, bson.M{"_id": id}); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error unblocking task", err.Error())
+			return
+		}
+
+		if task, err = findAccessibleTask(ctx, userID, id, model.RoleViewer); err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching task")
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Dependency removed successfully for "+username, task)
+	}
+}
+
+// GetProjectDependencies - Retrieves the dependency graph of a project's tasks, with the tasks in topological order
+// so that every task comes after the ones it is blocked by
+func GetProjectDependencies() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		if _, err := findProject(ctx, userID, id); err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching project")
+			return
+		}
+
+		tasks, err := findVisibleTasks(ctx, userID, bson.M{"user_id": userID, "project_id": id})
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error fetching tasks", err.Error())
+			return
+		}
+
+		var graph model.DependencyGraph
+		graph.Tasks, graph.Edges = sortByDependencies(tasks)
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Dependency graph of project for "+username, graph)
+	}
+}
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	model "task-manager/server/models"
+)
+
+func TestSortByDependencies(t *testing.T) {
+	ids := map[string]primitive.ObjectID{}
+	for _, name := range []string{"a", "b", "c", "d", "e", "outside"} {
+		ids[name] = primitive.NewObjectID()
+	}
+	// task builds a task titled name, blocked by the named prerequisites; the slice order stands for rank order
+	task := func(name string, blockedBy ...string) model.Task {
+		t := model.Task{ID: ids[name], Title: name, Rank: name}
+		for _, prerequisite := range blockedBy {
+			t.BlockedBy = append(t.BlockedBy, ids[prerequisite])
+		}
+		return t
+	}
+	edge := func(from, to string) model.DependencyEdge {
+		return model.DependencyEdge{From: ids[from], To: ids[to]}
+	}
+
+	tests := []struct {
+		name  string
+		tasks []model.Task
+		order []string
+		edges []model.DependencyEdge
+	}{
+		{
+			name:  "empty",
+			tasks: nil,
+			order: []string{},
+			edges: []model.DependencyEdge{},
+		},
+		{
+			name:  "no links keeps rank order",
+			tasks: []model.Task{task("a"), task("b"), task("c")},
+			order: []string{"a", "b", "c"},
+			edges: []model.DependencyEdge{},
+		},
+		{
+			name:  "prerequisite moves ahead of its dependent",
+			tasks: []model.Task{task("a", "c"), task("b"), task("c")},
+			order: []string{"b", "c", "a"},
+			edges: []model.DependencyEdge{edge("c", "a")},
+		},
+		{
+			name:  "chain",
+			tasks: []model.Task{task("a", "b"), task("b", "c"), task("c")},
+			order: []string{"c", "b", "a"},
+			edges: []model.DependencyEdge{edge("b", "a"), edge("c", "b")},
+		},
+		{
+			name:  "tasks unblocked together keep rank order",
+			tasks: []model.Task{task("a"), task("b", "a"), task("c", "a"), task("d")},
+			order: []string{"a", "d", "b", "c"},
+			edges: []model.DependencyEdge{edge("a", "b"), edge("a", "c")},
+		},
+		{
+			name:  "waits for every prerequisite",
+			tasks: []model.Task{task("a", "b", "c"), task("b"), task("c")},
+			order: []string{"b", "c", "a"},
+			edges: []model.DependencyEdge{edge("b", "a"), edge("c", "a")},
+		},
+		{
+			name:  "prerequisites outside the list are ignored",
+			tasks: []model.Task{task("a", "outside"), task("b")},
+			order: []string{"a", "b"},
+			edges: []model.DependencyEdge{},
+		},
+		{
+			name:  "cycle members are appended in rank order",
+			tasks: []model.Task{task("a", "c"), task("b"), task("c", "a"), task("d", "a"), task("e")},
+			order: []string{"b", "e", "a", "c", "d"},
+			edges: []model.DependencyEdge{edge("c", "a"), edge("a", "c"), edge("a", "d")},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			order, edges := sortByDependencies(test.tasks)
+			titles := make([]string, 0, len(order))
+			for _, task := range order {
+				titles = append(titles, task.Title)
+			}
+			if !reflect.DeepEqual(titles, test.order) {
+				t.Errorf("order = %v, want %v", titles, test.order)
+			}
+			if !reflect.DeepEqual(edges, test.edges) {
+				t.Errorf("edges = %v, want %v", edges, test.edges)
+			}
+		})
+	}
+}
@@ -0,0 +1,358 @@
+package controller
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	database "task-manager/server/database"
+	helper "task-manager/server/helpers"
+	model "task-manager/server/models"
+)
+
+// findProject - Loads a project owned by the user
+func findProject(ctx context.Context, userID string, id primitive.ObjectID) (model.Project, error) {
+	var project model.Project
+	err := database.GetProjectCollection().FindOne(ctx, helper.InWorkspace(ctx, bson.M{"_id": id, "user_id": userID})).Decode(&project)
+	if err == mongo.ErrNoDocuments {
+		return project, helper.NewRequestError(http.StatusNotFound, "Project not found", "No project found for the specified ID and user")
+	}
+	return project, err
+}
+
+// checkTaskProject - Ensures tasks can be filed under the given project
+func checkTaskProject(ctx context.Context, userID string, projectID primitive.ObjectID) error {
+	project, err := findProject(ctx, userID, projectID)
+	if err != nil {
+		return err
+	}
+	if project.IsFolder {
+		return helper.NewRequestError(http.StatusBadRequest, "Invalid project", "Tasks cannot be added directly to a folder")
+	}
+	if project.Archived {
+		return helper.NewRequestError(http.StatusBadRequest, "Invalid project", "Tasks cannot be added to an archived project")
+	}
+	return nil
+}
+
+// checkProjectParent - Ensures parentID is a folder of the user and that nesting projectID under it creates no cycle
+func checkProjectParent(ctx context.Context, userID string, projectID primitive.ObjectID, parentID primitive.ObjectID) error {
+	parent, err := findProject(ctx, userID, parentID)
+	if err != nil {
+		return err
+	}
+	if !parent.IsFolder {
+		return helper.NewRequestError(http.StatusBadRequest, "Invalid parent", "Projects can only be nested inside folders")
+	}
+
+	// Walk up from the new parent; meeting the project itself means it would become its own ancestor
+	for ancestor := &parent; ; {
+		if ancestor.ID == projectID {
+			return helper.NewRequestError(http.StatusBadRequest, "Invalid parent", "A folder cannot be nested inside itself")
+		}
+		if ancestor.ParentID == nil {
+			return nil
+		}
+		next, err := findProject(ctx, userID, *ancestor.ParentID)
+		if err != nil {
+			return err
+		}
+		ancestor = &next
+	}
+}
+
+// GetProjects - Retrieves the user's projects and folders, hiding archived ones unless requested
+func GetProjects() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		includeArchived, err := parseBoolParam(c, "archived")
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		filter := helper.InWorkspace(ctx, bson.M{"user_id": userID})
+		if !includeArchived {
+			filter["archived"] = false
+		}
+
+		opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+		cursor, err := database.GetProjectCollection().Find(ctx, filter, opts)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error fetching projects", err.Error())
+			return
+		}
+		defer cursor.Close(ctx)
+
+		projects := []model.Project{}
+		if err = cursor.All(ctx, &projects); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error decoding projects", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Projects for "+username, projects)
+	}
+}
+
+// GetProjectByID - Retrieves a single project by its ID
+func GetProjectByID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		project, err := findProject(ctx, userID, id)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching project")
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Project for "+username, project)
+	}
+}
+
+// PostProject - Creates a project or folder from JSON received in the request body
+func PostProject() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		var newProject model.Project
+		if err := c.ShouldBindJSON(&newProject); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid JSON input", err.Error())
+			return
+		}
+
+		newProject.ID = primitive.NewObjectID()
+		newProject.UserID = userID
+		newProject.Created = time.Now().UTC()
+		newProject.Updated = time.Time{}
+
+		if err := validate.Struct(newProject); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Validation error", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		if err := requireWorkspaceRole(ctx, model.WorkspaceMember); err != nil {
+			helper.RespondWithRequestError(c, err, "Error creating project")
+			return
+		}
+		newProject.WorkspaceID, _, _ = helper.WorkspaceFromContext(ctx)
+
+		if newProject.ParentID != nil {
+			if err := checkProjectParent(ctx, userID, newProject.ID, *newProject.ParentID); err != nil {
+				helper.RespondWithRequestError(c, err, "Error checking parent folder")
+				return
+			}
+		}
+
+		if _, err := database.GetProjectCollection().InsertOne(ctx, newProject); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error inserting project", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusCreated, "Project created successfully", newProject)
+	}
+}
+
+// UpdateProject - Renames, archives or moves the project with the specified ID
+func UpdateProject() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		var updatedFields struct {
+			Name     *string                            `json:"name" validate:"omitempty,min=1,max=100"`
+			Archived *bool                              `json:"archived"`
+			ParentID model.Nullable[primitive.ObjectID] `json:"parent_id"`
+		}
+
+		if err := c.ShouldBindJSON(&updatedFields); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid JSON input", err.Error())
+			return
+		}
+
+		if err := validate.Struct(updatedFields); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Validation error", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		update := bson.M{"updated_at": time.Now().UTC()}
+		unset := bson.M{}
+		if updatedFields.Name != nil {
+			update["name"] = *updatedFields.Name
+		}
+		if updatedFields.Archived != nil {
+			update["archived"] = *updatedFields.Archived
+		}
+		if updatedFields.ParentID.Set {
+			if updatedFields.ParentID.Value == nil {
+				unset["parent_id"] = ""
+			} else {
+				if err := checkProjectParent(ctx, userID, id, *updatedFields.ParentID.Value); err != nil {
+					helper.RespondWithRequestError(c, err, "Error checking parent folder")
+					return
+				}
+				update["parent_id"] = *updatedFields.ParentID.Value
+			}
+		}
+
+		changes := bson.M{"$set": update}
+		if len(unset) > 0 {
+			changes["$unset"] = unset
+		}
+
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		var project model.Project
+		err = database.GetProjectCollection().FindOneAndUpdate(ctx, helper.InWorkspace(ctx, bson.M{"_id": id, "user_id": userID}), changes, opts).Decode(&project)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				helper.RespondWithError(c, http.StatusNotFound, "Project not found", "No project found for the specified ID and user "+username)
+				return
+			}
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error updating project", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Project updated successfully for "+username, project)
+	}
+}
+
+// DeleteProject - Deletes a project. With ?mode=cascade its tasks are moved to the trash too,
+// otherwise (mode=inbox, the default) they are moved back to the inbox.
+// Projects inside a deleted folder move up to the folder's parent.
+func DeleteProject() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		mode := c.DefaultQuery("mode", "inbox")
+		if mode != "inbox" && mode != "cascade" {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid delete mode", "mode must be inbox or cascade")
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		project, err := findProject(ctx, userID, id)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching project")
+			return
+		}
+
+		projectCollection := database.GetProjectCollection()
+		childFilter := helper.InWorkspace(ctx, bson.M{"user_id": userID, "parent_id": id})
+		var moveChildren bson.M
+		if project.ParentID != nil {
+			moveChildren = bson.M{"$set": bson.M{"parent_id": *project.ParentID}}
+		} else {
+			moveChildren = bson.M{"$unset": bson.M{"parent_id": ""}}
+		}
+		if _, err := projectCollection.UpdateMany(ctx, childFilter, moveChildren); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error moving nested projects", err.Error())
+			return
+		}
+
+		taskCollection := database.GetTaskCollection()
+		taskFilter := helper.InWorkspace(ctx, bson.M{"user_id": userID, "project_id": id})
+		deletedAt := time.Now().UTC().Truncate(time.Millisecond)
+		if mode == "cascade" {
+			_, err = taskCollection.UpdateMany(ctx, helper.NotDeleted(taskFilter), bson.M{"$set": bson.M{"deleted_at": deletedAt}})
+		} else {
+			_, err = taskCollection.UpdateMany(ctx, taskFilter, bson.M{"$unset": bson.M{"project_id": ""}})
+		}
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error updating project tasks", err.Error())
+			return
+		}
+
+		if mode == "cascade" {
+			var trashed []model.Task
+			cursor, err := taskCollection.Find(ctx, helper.InWorkspace(ctx, bson.M{"user_id": userID, "project_id": id, "deleted_at": deletedAt}), options.Find().SetProjection(bson.M{"_id": 1}))
+			if err == nil {
+				err = cursor.All(ctx, &trashed)
+			}
+			if err == nil {
+				err = helper.CancelReminders(ctx, taskIDs(trashed))
+			}
+			if err != nil {
+				log.Printf("Error cancelling reminders of project %s: %v", id.Hex(), err)
+			}
+			// Tasks outside the project may have been waiting for the trashed ones
+			if err := syncDependents(ctx, taskIDs(trashed)); err != nil {
+				log.Printf("Error unblocking dependents of project %s: %v", id.Hex(), err)
+			}
+		}
+
+		if _, err := database.GetBoardCollection().DeleteOne(ctx, bson.M{"user_id": userID, "project_id": id}); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error deleting project board", err.Error())
+			return
+		}
+
+		if _, err := database.GetShareCollection().DeleteMany(ctx, bson.M{"resource": model.ShareProject, "resource_id": id}); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error deleting project shares", err.Error())
+			return
+		}
+
+		if _, err := projectCollection.DeleteOne(ctx, helper.InWorkspace(ctx, bson.M{"_id": id, "user_id": userID})); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error deleting project", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Project deleted successfully", nil)
+	}
+}
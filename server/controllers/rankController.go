@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	database "task-manager/server/database"
+	helper "task-manager/server/helpers"
+	model "task-manager/server/models"
+)
+
+// sameObjectID - Reports whether two optional IDs are both unset or both the same ID
+func sameObjectID(a, b *primitive.ObjectID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// rankAtEnd - Returns a rank that places a task after all of its siblings
+func rankAtEnd(ctx context.Context, userID string, projectID *primitive.ObjectID, parentID *primitive.ObjectID) (string, error) {
+	lastRank, err := helper.LastRank(ctx, helper.InWorkspace(ctx, helper.SiblingFilter(userID, projectID, parentID)))
+	if err != nil {
+		return "", fmt.Errorf("ranking task: %w", err)
+	}
+	return helper.RankBetween(lastRank, "")
+}
+
+// neighbourRank - Finds the rank of the sibling next to anchor, below it if before is set and above it otherwise.
+// The task being moved is skipped, and "" is returned when anchor is at that end of the list.
+func neighbourRank(ctx context.Context, userID string, anchor model.Task, movingID primitive.ObjectID, before bool) (string, error) {
+	filter := helper.InWorkspace(ctx, helper.SiblingFilter(userID, anchor.ProjectID, anchor.ParentID))
+	filter["_id"] = bson.M{"$ne": movingID}
+
+	direction := 1
+	if before {
+		filter["rank"] = bson.M{"$lt": anchor.Rank}
+		direction = -1
+	} else {
+		filter["rank"] = bson.M{"$gt": anchor.Rank}
+	}
+
+	var neighbour model.Task
+	opts := options.FindOne().SetSort(bson.D{{Key: "rank", Value: direction}})
+	err := database.GetTaskCollection().FindOne(ctx, filter, opts).Decode(&neighbour)
+	if err == mongo.ErrNoDocuments {
+		return "", nil
+	}
+	return neighbour.Rank, err
+}
+
+// RankTask - Places a task directly before or after one of its siblings in the user's manual order.
+// Only the moved task is written; a sibling list without ranks yet is ranked in creation order first.
+func RankTask() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		var request struct {
+			Before *primitive.ObjectID `json:"before"`
+			After  *primitive.ObjectID `json:"after"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid JSON input", err.Error())
+			return
+		}
+		if (request.Before == nil) == (request.After == nil) {
+			helper.RespondWithError(c, http.StatusBadRequest, "Validation error", "Give exactly one of before or after")
+			return
+		}
+
+		anchorID := request.After
+		if request.Before != nil {
+			anchorID = request.Before
+		}
+		if *anchorID == id {
+			helper.RespondWithError(c, http.StatusBadRequest, "Validation error", "A task cannot be placed next to itself")
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		task, err := findTask(ctx, userID, id)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching task")
+			return
+		}
+		anchor, err := findTask(ctx, userID, *anchorID)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching sibling task")
+			return
+		}
+		if !sameObjectID(task.ProjectID, anchor.ProjectID) || !sameObjectID(task.ParentID, anchor.ParentID) {
+			helper.RespondWithError(c, http.StatusBadRequest, "Validation error", "Tasks can only be placed next to a task with the same parent and project")
+			return
+		}
+
+		// Tasks created before manual ordering existed have no rank to place against
+		if anchor.Rank == "" {
+			if err := helper.RebalanceRanks(ctx, helper.InWorkspace(ctx, helper.SiblingFilter(userID, anchor.ProjectID, anchor.ParentID))); err != nil {
+				helper.RespondWithError(c, http.StatusInternalServerError, "Error ranking tasks", err.Error())
+				return
+			}
+			if anchor, err = findTask(ctx, userID, anchor.ID); err != nil {
+				helper.RespondWithRequestError(c, err, "Error fetching sibling task")
+				return
+			}
+		}
+
+		neighbour, err := neighbourRank(ctx, userID, anchor, id, request.Before != nil)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error fetching sibling tasks", err.Error())
+			return
+		}
+
+		var rank string
+		if request.Before != nil {
+			rank, err = helper.RankBetween(neighbour, anchor.Rank)
+		} else {
+			rank, err = helper.RankBetween(anchor.Rank, neighbour)
+		}
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error ranking task", err.Error())
+			return
+		}
+
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		err = database.GetTaskCollection().FindOneAndUpdate(ctx,
+			helper.NotDeleted(helper.InWorkspace(ctx, bson.M{"_id": id, "user_id": userID})),
+			bson.M{"$set": bson.M{"rank": rank, "updated_at": time.Now().UTC()}},
+			opts,
+		).Decode(&task)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error moving task", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Task moved successfully for "+username, task)
+	}
+}
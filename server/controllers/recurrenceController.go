@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	database "task-manager/server/database"
+	helper "task-manager/server/helpers"
+	model "task-manager/server/models"
+)
+
+const maxPreviewOccurrences = 50
+
+// prepareRecurrence - Validates a task's recurrence and fills in its series details.
+// A new series is anchored on the task's due date; an existing one keeps its ID and occurrence count.
+func prepareRecurrence(recurrence *model.Recurrence, taskID primitive.ObjectID, dueAt *time.Time, existing *model.Recurrence) error {
+	if dueAt == nil {
+		return helper.NewRequestError(http.StatusBadRequest, "Validation error", "Recurring tasks need a due_at")
+	}
+
+	if recurrence.Mode == "" {
+		recurrence.Mode = model.RepeatFromDue
+	}
+	if recurrence.Timezone == "" {
+		recurrence.Timezone = "UTC"
+	}
+	if err := validate.Struct(recurrence); err != nil {
+		return helper.NewRequestError(http.StatusBadRequest, "Validation error", err.Error())
+	}
+	if err := helper.ValidateRecurrence(*recurrence); err != nil {
+		return helper.NewRequestError(http.StatusBadRequest, "Invalid recurrence", err.Error())
+	}
+
+	recurrence.SeriesStart = dueAt.UTC()
+	if existing != nil {
+		recurrence.SeriesID = existing.SeriesID
+		recurrence.Occurrence = existing.Occurrence
+	} else {
+		recurrence.SeriesID = taskID
+		recurrence.Occurrence = 1
+	}
+	return nil
+}
+
+// createNextOccurrence - Adds the next task of a recurring series once the current one is done.
+// The completed task records the ID of its successor, so completing it again never creates a second one.
+func createNextOccurrence(ctx context.Context, task model.Task) error {
+	if task.Recurrence == nil || task.Status != model.StatusDone || task.NextOccurrenceID != nil || task.DueAt == nil {
+		return nil
+	}
+
+	completedAt := time.Now().UTC()
+	if task.CompletedAt != nil {
+		completedAt = *task.CompletedAt
+	}
+
+	occurrences, err := helper.NextOccurrences(*task.Recurrence, *task.DueAt, completedAt, 1)
+	if err != nil || len(occurrences) == 0 {
+		return err
+	}
+	nextDue := occurrences[0]
+
+	recurrence := *task.Recurrence
+	recurrence.Occurrence++
+
+	rank, err := rankAtEnd(ctx, task.UserID, task.ProjectID, task.ParentID)
+	if err != nil {
+		return err
+	}
+
+	next := model.Task{
+		ID:                    primitive.NewObjectID(),
+		WorkspaceID:           task.WorkspaceID,
+		UserID:                task.UserID,
+		Username:              task.Username,
+		Title:                 task.Title,
+		ProjectID:             task.ProjectID,
+		ParentID:              task.ParentID,
+		Rank:                  rank,
+		Tags:                  task.Tags,
+		Priority:              task.Priority,
+		Status:                model.StatusTodo,
+		ChecklistAutoComplete: task.ChecklistAutoComplete,
+		ReminderOffsets:       task.ReminderOffsets,
+		DueAt:                 &nextDue,
+		Recurrence:            &recurrence,
+		Created:               time.Now().UTC(),
+	}
+	// Whoever took on the task keeps its next occurrence too
+	if task.AssignmentStatus == model.AssignmentAccepted {
+		next.AssigneeID = task.AssigneeID
+		next.AssignmentStatus = task.AssignmentStatus
+		next.AssignedBy = task.AssignedBy
+		next.AssignedAt = task.AssignedAt
+	}
+	for _, item := range task.Checklist {
+		next.Checklist = append(next.Checklist, model.ChecklistItem{Text: item.Text})
+	}
+	next.Checklist = normalizeChecklist(next.Checklist)
+	if task.StartAt != nil {
+		startAt := task.StartAt.Add(nextDue.Sub(*task.DueAt))
+		next.StartAt = &startAt
+	}
+
+	// The occurrence is inserted before the completed task points at it, so a failed insert leaves the series
+	// free to try again. Whoever loses the race to point at their occurrence removes it again.
+	collection := database.GetTaskCollection()
+	if _, err := collection.InsertOne(ctx, next); err != nil {
+		return err
+	}
+	claimed, err := collection.UpdateOne(ctx,
+		helper.InWorkspace(ctx, bson.M{"_id": task.ID, "next_occurrence_id": bson.M{"$exists": false}}),
+		bson.M{"$set": bson.M{"next_occurrence_id": next.ID}},
+	)
+	if err != nil || claimed.ModifiedCount == 0 {
+		if _, deleteErr := collection.DeleteOne(ctx, bson.M{"_id": next.ID}); deleteErr != nil {
+			log.Printf("Error removing unclaimed occurrence %s: %v", next.ID.Hex(), deleteErr)
+		}
+		return err
+	}
+	return helper.ScheduleReminders(ctx, next)
+}
+
+// GetTaskOccurrences - Previews the due dates of the next occurrences of a recurring task (?count=, default 5)
+func GetTaskOccurrences() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		count, err := strconv.Atoi(c.DefaultQuery("count", "5"))
+		if err != nil || count < 1 || count > maxPreviewOccurrences {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid query parameters", "count must be between 1 and "+strconv.Itoa(maxPreviewOccurrences))
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		task, err := findTask(ctx, userID, id)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching task")
+			return
+		}
+		if task.Recurrence == nil || task.DueAt == nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Task does not repeat", "The task has no recurrence rule")
+			return
+		}
+
+		occurrences, err := helper.NextOccurrences(*task.Recurrence, *task.DueAt, *task.DueAt, count)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid recurrence", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Upcoming occurrences for "+username, occurrences)
+	}
+}
@@ -0,0 +1,377 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	database "task-manager/server/database"
+	helper "task-manager/server/helpers"
+	model "task-manager/server/models"
+)
+
+// revisionFields - The task fields whose changes are recorded in its history and can be reverted.
+// Bookkeeping fields such as rank, updated_at and deleted_at are left out.
+var revisionFields = []string{
+	"title", "status", "priority", "due_at", "start_at", "completed_at", "project_id", "parent_id",
+	"tags", "checklist", "checklist_auto_complete", "recurrence", "reminder_offsets",
+}
+
+// maxRevisionRetries - How often recording a revision is retried when another one claimed its number first
+const maxRevisionRetries = 3
+
+// taskDocument - Converts a task to the document it is stored as, with embedded documents as maps
+// so its values compare and encode the same way as the ones read back from revisions
+func taskDocument(task model.Task) (bson.M, error) {
+	raw, err := bson.Marshal(task)
+	if err != nil {
+		return nil, err
+	}
+	decoder, err := bson.NewDecoder(bsonrw.NewBSONDocumentReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	decoder.DefaultDocumentM()
+
+	var doc bson.M
+	err = decoder.Decode(&doc)
+	return doc, err
+}
+
+// diffTaskDocuments - Lists the revision fields whose values differ between two task documents
+func diffTaskDocuments(before, after bson.M) []model.FieldChange {
+	changes := []model.FieldChange{}
+	for _, field := range revisionFields {
+		if !reflect.DeepEqual(before[field], after[field]) {
+			changes = append(changes, model.FieldChange{Field: field, Old: before[field], New: after[field]})
+		}
+	}
+	return changes
+}
+
+// recordRevision - Adds a revision to the task's history for the change from before to after.
+// before is nil when the task was just created. Changes that touch no revision field are not recorded.
+func recordRevision(ctx context.Context, authorID string, author string, action model.RevisionAction, before *model.Task, after model.Task, revertedTo int) error {
+	beforeDoc := bson.M{}
+	if before != nil {
+		var err error
+		if beforeDoc, err = taskDocument(*before); err != nil {
+			return err
+		}
+	}
+	afterDoc, err := taskDocument(after)
+	if err != nil {
+		return err
+	}
+
+	changes := diffTaskDocuments(beforeDoc, afterDoc)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	revision := model.Revision{
+		TaskID:     after.ID,
+		UserID:     after.UserID,
+		Action:     action,
+		AuthorID:   authorID,
+		Author:     author,
+		Changes:    changes,
+		RevertedTo: revertedTo,
+		Created:    time.Now().UTC(),
+	}
+
+	collection := database.GetRevisionCollection()
+	for attempt := 0; ; attempt++ {
+		var last model.Revision
+		opts := options.FindOne().SetSort(bson.D{{Key: "number", Value: -1}}).SetProjection(bson.M{"number": 1})
+		err := collection.FindOne(ctx, bson.M{"task_id": after.ID}, opts).Decode(&last)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return err
+		}
+		revision.Number = last.Number + 1
+
+		// The unique index on task_id and number turns a concurrent revision into a retry
+		_, err = collection.InsertOne(ctx, revision)
+		if !mongo.IsDuplicateKeyError(err) || attempt == maxRevisionRetries {
+			return err
+		}
+	}
+}
+
+// logRevision - Records a revision, logging rather than failing the change it describes
+func logRevision(ctx context.Context, authorID string, author string, action model.RevisionAction, before *model.Task, after model.Task) {
+	if err := recordRevision(ctx, authorID, author, action, before, after, 0); err != nil {
+		log.Printf("Error recording revision for task %s: %v", after.ID.Hex(), err)
+	}
+}
+
+// revertTask - Restores the revision fields of a task the user can edit to their values right after revision number.
+// The values are rebuilt by undoing every later revision, newest first, and the revert is recorded as a revision itself.
+func revertTask(ctx context.Context, userID string, username string, id primitive.ObjectID, number int) (model.Task, error) {
+	current, err := findAccessibleTask(ctx, userID, id, model.RoleEditor)
+	if err != nil {
+		return current, err
+	}
+
+	collection := database.GetRevisionCollection()
+	count, err := collection.CountDocuments(ctx, bson.M{"task_id": id, "number": number})
+	if err != nil {
+		return current, fmt.Errorf("fetching revision: %w", err)
+	}
+	if count == 0 {
+		return current, helper.NewRequestError(http.StatusNotFound, "Revision not found", "No revision "+strconv.Itoa(number)+" found for the task")
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "number", Value: -1}})
+	cursor, err := collection.Find(ctx, bson.M{"task_id": id, "number": bson.M{"$gt": number}}, opts)
+	if err != nil {
+		return current, fmt.Errorf("fetching revisions: %w", err)
+	}
+	var later []model.Revision
+	if err := cursor.All(ctx, &later); err != nil {
+		return current, fmt.Errorf("decoding revisions: %w", err)
+	}
+
+	currentDoc, err := taskDocument(current)
+	if err != nil {
+		return current, err
+	}
+	targetDoc := bson.M{}
+	for key, value := range currentDoc {
+		targetDoc[key] = value
+	}
+	for _, revision := range later {
+		for _, change := range revision.Changes {
+			if change.Old == nil {
+				delete(targetDoc, change.Field)
+			} else {
+				targetDoc[change.Field] = change.Old
+			}
+		}
+	}
+
+	changes := diffTaskDocuments(currentDoc, targetDoc)
+	if len(changes) == 0 {
+		return current, helper.NewRequestError(http.StatusBadRequest, "Nothing to revert", "The task already matches revision "+strconv.Itoa(number))
+	}
+	return applyTaskChanges(ctx, userID, username, current, changes, model.RevisionRevert, number)
+}
+
+// applyTaskChanges - Writes the New value of every change to the task, or removes the field when it is nil,
+// and records the result as a revision. The write only succeeds if the task is unchanged since current was read.
+// The project and parent the task ends up in are checked like in any other update, and it keeps its reminders
+// and status side effects in step.
+func applyTaskChanges(ctx context.Context, userID string, username string, current model.Task, changes []model.FieldChange, action model.RevisionAction, revertedTo int) (model.Task, error) {
+	targetDoc, err := taskDocument(current)
+	if err != nil {
+		return current, err
+	}
+	ownerID := current.UserID
+
+	now := time.Now().UTC()
+	update := bson.M{"updated_at": now}
+	unset := bson.M{}
+	for _, change := range changes {
+		if change.New == nil {
+			delete(targetDoc, change.Field)
+			unset[change.Field] = ""
+		} else {
+			targetDoc[change.Field] = change.New
+			update[change.Field] = change.New
+		}
+	}
+
+	// Decode the target so the places it points to can be checked like any other update
+	raw, err := bson.Marshal(targetDoc)
+	if err != nil {
+		return current, err
+	}
+	var target model.Task
+	if err := bson.Unmarshal(raw, &target); err != nil {
+		return current, err
+	}
+
+	if target.Status != current.Status {
+		// The status goes through the workflow like in any other update, but a completion time that
+		// comes with the changes is kept rather than replaced with now
+		statusUpdate, statusUnset := bson.M{}, bson.M{}
+		if err := applyStatusChange(current, target.Status, now, statusUpdate, statusUnset); err != nil {
+			return current, err
+		}
+		changed := make(map[string]bool, len(changes))
+		for _, change := range changes {
+			changed[change.Field] = true
+		}
+		for field, value := range statusUpdate {
+			if !changed[field] {
+				update[field] = value
+			}
+		}
+		for field := range statusUnset {
+			if !changed[field] {
+				unset[field] = ""
+			}
+		}
+		if err := checkBlockedStatus(ctx, current, target.Status, update, unset); err != nil {
+			return current, err
+		}
+		// Reverting to todo while prerequisites are open blocks the task again
+		if update["status"] == model.StatusBlocked {
+			target.Status = model.StatusBlocked
+		}
+	}
+	if target.ProjectID != nil && !sameObjectID(current.ProjectID, target.ProjectID) {
+		if err := checkTaskProject(ctx, ownerID, *target.ProjectID); err != nil {
+			return current, err
+		}
+	}
+	if target.ParentID != nil && !sameObjectID(current.ParentID, target.ParentID) {
+		if _, err := checkTaskParent(ctx, ownerID, current.ID, *target.ParentID); err != nil {
+			return current, err
+		}
+	}
+	if !sameObjectID(current.ProjectID, target.ProjectID) || !sameObjectID(current.ParentID, target.ParentID) {
+		if update["rank"], err = rankAtEnd(ctx, ownerID, target.ProjectID, target.ParentID); err != nil {
+			return current, err
+		}
+	}
+	if target.Status != current.Status || !sameObjectID(current.ProjectID, target.ProjectID) {
+		if err := checkWIPLimit(ctx, ownerID, current, target.ProjectID, target.Status); err != nil {
+			return current, err
+		}
+	}
+	if _, ok := update["tags"]; ok {
+		if err := registerTags(ctx, ownerID, target.Tags); err != nil {
+			return current, fmt.Errorf("registering tags: %w", err)
+		}
+	}
+
+	changeSet := bson.M{"$set": update}
+	if len(unset) > 0 {
+		changeSet["$unset"] = unset
+	}
+
+	filter := helper.NotDeleted(helper.InWorkspace(ctx, bson.M{"_id": current.ID, "user_id": ownerID, "updated_at": current.Updated}))
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var task model.Task
+	if err := database.GetTaskCollection().FindOneAndUpdate(ctx, filter, changeSet, opts).Decode(&task); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return current, helper.NewRequestError(http.StatusConflict, "Task was modified concurrently", "The task changed while updating it, please retry")
+		}
+		return current, fmt.Errorf("updating task: %w", err)
+	}
+
+	if err := recordRevision(ctx, userID, username, action, &current, task, revertedTo); err != nil {
+		log.Printf("Error recording revision for task %s: %v", task.ID.Hex(), err)
+	}
+	if err := afterStatusChange(ctx, ownerID, task, current.Status, now); err != nil {
+		return task, fmt.Errorf("applying status change: %w", err)
+	}
+	if err := helper.ScheduleReminders(ctx, task); err != nil {
+		log.Printf("Error scheduling reminders for task %s: %v", task.ID.Hex(), err)
+	}
+	return task, nil
+}
+
+// GetTaskHistory - Retrieves the revisions of a task, newest first.
+// Older revisions are fetched with ?before=<number> set to the last number received.
+func GetTaskHistory() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		limit, err := parsePageSize(c)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+			return
+		}
+
+		filter := bson.M{"task_id": id}
+		if raw := c.Query("before"); raw != "" {
+			before, err := strconv.Atoi(raw)
+			if err != nil || before < 1 {
+				helper.RespondWithError(c, http.StatusBadRequest, "Invalid query parameters", "before must be a revision number")
+				return
+			}
+			filter["number"] = bson.M{"$lt": before}
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		if _, err := findAccessibleTask(ctx, userID, id, model.RoleViewer); err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching task")
+			return
+		}
+
+		opts := options.Find().SetSort(bson.D{{Key: "number", Value: -1}}).SetLimit(int64(limit))
+		cursor, err := database.GetRevisionCollection().Find(ctx, filter, opts)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error fetching history", err.Error())
+			return
+		}
+		defer cursor.Close(ctx)
+
+		revisions := []model.Revision{}
+		if err := cursor.All(ctx, &revisions); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error decoding history", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Task history for "+username, revisions)
+	}
+}
+
+// RevertTask - Reverts a task to how it was right after the given revision
+func RevertTask() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		number, err := strconv.Atoi(c.Param("number"))
+		if err != nil || number < 1 {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid revision number", "The revision must be a positive number")
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		task, err := revertTask(ctx, userID, username, id, number)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error reverting task")
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Task reverted to revision "+strconv.Itoa(number)+" for "+username, task)
+	}
+}
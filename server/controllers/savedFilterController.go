@@ -0,0 +1,264 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	database "task-manager/server/database"
+	helper "task-manager/server/helpers"
+	model "task-manager/server/models"
+)
+
+// builtInFilters - Smart lists every user has, expressed in the filter language and evaluated at request time
+var builtInFilters = []model.SavedFilter{
+	{Key: "today", Name: "Today", Query: "is:open due<=today", Sort: "due_at,-priority", Icon: "calendar-today", BuiltIn: true},
+	{Key: "upcoming", Name: "Upcoming", Query: "is:open due>today due<=+7d", Sort: "due_at,-priority", Icon: "calendar-week", BuiltIn: true},
+	{Key: "overdue", Name: "Overdue", Query: "is:overdue", Sort: "due_at", Icon: "alarm", BuiltIn: true},
+	{Key: "no_due_date", Name: "No Due Date", Query: "is:open no:due", Sort: "-priority,-created_at", Icon: "inbox", BuiltIn: true},
+}
+
+// checkSavedFilter - Ensures a filter's query and sort parse, so a saved view never fails when it is opened
+func checkSavedFilter(filter model.SavedFilter) error {
+	if err := validate.Struct(filter); err != nil {
+		return helper.NewRequestError(http.StatusBadRequest, "Validation error", err.Error())
+	}
+	if _, err := parseTaskFilter(filter.Query, time.Now().UTC()); err != nil {
+		return helper.NewRequestError(http.StatusBadRequest, "Invalid filter query", err.Error())
+	}
+	if _, err := parseTaskSort(filter.Sort); err != nil {
+		return helper.NewRequestError(http.StatusBadRequest, "Invalid filter sort", err.Error())
+	}
+	return nil
+}
+
+// findSavedFilter - Loads a saved filter of the user, or a built-in smart list by its key
+func findSavedFilter(ctx context.Context, userID string, idOrKey string) (model.SavedFilter, error) {
+	for _, builtIn := range builtInFilters {
+		if builtIn.Key == idOrKey {
+			return builtIn, nil
+		}
+	}
+
+	var filter model.SavedFilter
+	id, err := primitive.ObjectIDFromHex(idOrKey)
+	if err != nil {
+		return filter, helper.NewRequestError(http.StatusBadRequest, "Invalid ID format", "Expected a saved filter ID or one of today, upcoming, overdue, no_due_date")
+	}
+
+	err = database.GetSavedFilterCollection().FindOne(ctx, bson.M{"_id": id, "user_id": userID}).Decode(&filter)
+	if err == mongo.ErrNoDocuments {
+		return filter, helper.NewRequestError(http.StatusNotFound, "Saved filter not found", "No saved filter found for the specified ID and user")
+	}
+	return filter, err
+}
+
+// GetSavedFilters - Retrieves the built-in smart lists followed by the user's saved filters
+func GetSavedFilters() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+		cursor, err := database.GetSavedFilterCollection().Find(ctx, bson.M{"user_id": userID}, opts)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error fetching saved filters", err.Error())
+			return
+		}
+		defer cursor.Close(ctx)
+
+		var saved []model.SavedFilter
+		if err = cursor.All(ctx, &saved); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error decoding saved filters", err.Error())
+			return
+		}
+
+		filters := append(append([]model.SavedFilter{}, builtInFilters...), saved...)
+		helper.RespondWithSuccess(c, http.StatusOK, "Saved filters for "+username, filters)
+	}
+}
+
+// GetSavedFilterByID - Retrieves a saved filter, or a built-in smart list by its key
+func GetSavedFilterByID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		filter, err := findSavedFilter(ctx, userID, c.Param("id"))
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching saved filter")
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Saved filter for "+username, filter)
+	}
+}
+
+// PostSavedFilter - Saves a named task query
+func PostSavedFilter() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		var newFilter model.SavedFilter
+		if err := c.ShouldBindJSON(&newFilter); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid JSON input", err.Error())
+			return
+		}
+
+		newFilter.ID = primitive.NewObjectID()
+		newFilter.Key = ""
+		newFilter.BuiltIn = false
+		newFilter.UserID = userID
+		newFilter.Created = time.Now().UTC()
+		newFilter.Updated = time.Time{}
+
+		if err := checkSavedFilter(newFilter); err != nil {
+			helper.RespondWithRequestError(c, err, "Error checking saved filter")
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		if _, err := database.GetSavedFilterCollection().InsertOne(ctx, newFilter); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				helper.RespondWithError(c, http.StatusConflict, "Saved filter already exists", "A saved filter named "+newFilter.Name+" already exists")
+				return
+			}
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error inserting saved filter", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusCreated, "Saved filter created successfully", newFilter)
+	}
+}
+
+// UpdateSavedFilter - Changes a saved filter's name, query, sort or icon. Built-in smart lists cannot be changed.
+func UpdateSavedFilter() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		var updatedFields struct {
+			Name  *string `json:"name"`
+			Query *string `json:"query"`
+			Sort  *string `json:"sort"`
+			Icon  *string `json:"icon"`
+		}
+		if err := c.ShouldBindJSON(&updatedFields); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid JSON input", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		filter, err := findSavedFilter(ctx, userID, c.Param("id"))
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching saved filter")
+			return
+		}
+		if filter.BuiltIn {
+			helper.RespondWithError(c, http.StatusBadRequest, "Built-in filters cannot be changed", "Save a copy of the filter instead")
+			return
+		}
+
+		if updatedFields.Name != nil {
+			filter.Name = *updatedFields.Name
+		}
+		if updatedFields.Query != nil {
+			filter.Query = *updatedFields.Query
+		}
+		if updatedFields.Sort != nil {
+			filter.Sort = *updatedFields.Sort
+		}
+		if updatedFields.Icon != nil {
+			filter.Icon = *updatedFields.Icon
+		}
+		if err := checkSavedFilter(filter); err != nil {
+			helper.RespondWithRequestError(c, err, "Error checking saved filter")
+			return
+		}
+
+		update := bson.M{
+			"name":       filter.Name,
+			"query":      filter.Query,
+			"sort":       filter.Sort,
+			"icon":       filter.Icon,
+			"updated_at": time.Now().UTC(),
+		}
+
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		err = database.GetSavedFilterCollection().FindOneAndUpdate(ctx, bson.M{"_id": filter.ID, "user_id": userID}, bson.M{"$set": update}, opts).Decode(&filter)
+		if err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				helper.RespondWithError(c, http.StatusConflict, "Saved filter already exists", "A saved filter named "+filter.Name+" already exists")
+				return
+			}
+			if err == mongo.ErrNoDocuments {
+				helper.RespondWithError(c, http.StatusNotFound, "Saved filter not found", "No saved filter found for the specified ID and user "+username)
+				return
+			}
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error updating saved filter", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Saved filter updated successfully for "+username, filter)
+	}
+}
+
+// DeleteSavedFilter - Removes a saved filter. Built-in smart lists cannot be removed.
+func DeleteSavedFilter() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID not found in context")
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		filter, err := findSavedFilter(ctx, userID, c.Param("id"))
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching saved filter")
+			return
+		}
+		if filter.BuiltIn {
+			helper.RespondWithError(c, http.StatusBadRequest, "Built-in filters cannot be deleted", "Only saved filters can be deleted")
+			return
+		}
+
+		if _, err := database.GetSavedFilterCollection().DeleteOne(ctx, bson.M{"_id": filter.ID, "user_id": userID}); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error deleting saved filter", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Saved filter deleted successfully", nil)
+	}
+}
@@ -0,0 +1,224 @@
+package controller
+
+import (
+	"html"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	database "task-manager/server/database"
+	helper "task-manager/server/helpers"
+	model "task-manager/server/models"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	maxSearchLength    = 200
+)
+
+// searchQuery - A parsed search string: plain words, "quoted phrases", prefix* terms and -excluded words or -"phrases"
+type searchQuery struct {
+	Words    []string
+	Phrases  []string
+	Prefixes []string
+	Excluded []string
+}
+
+// SearchResult is a task matched by /tasks/search, with its relevance and a highlighted title
+type SearchResult struct {
+	Task      model.Task `json:"task"`
+	Score     float64    `json:"score"`
+	Highlight string     `json:"highlight"`
+}
+
+var searchTokenPattern = regexp.MustCompile(`-?"[^"]*"|\S+`)
+
+// parseSearchQuery - Splits the raw search string into its kinds of terms
+func parseSearchQuery(raw string) searchQuery {
+	var query searchQuery
+	for _, token := range searchTokenPattern.FindAllString(raw, -1) {
+		switch {
+		case strings.HasPrefix(token, `"`):
+			if phrase := strings.TrimSpace(strings.Trim(token, `"`)); phrase != "" {
+				query.Phrases = append(query.Phrases, phrase)
+			}
+		case strings.HasPrefix(token, "-"):
+			if word := strings.TrimSpace(strings.Trim(token[1:], `"`)); word != "" {
+				query.Excluded = append(query.Excluded, word)
+			}
+		case strings.HasSuffix(token, "*"):
+			if prefix := strings.TrimRight(token, "*"); prefix != "" {
+				query.Prefixes = append(query.Prefixes, prefix)
+			}
+		default:
+			query.Words = append(query.Words, token)
+		}
+	}
+	return query
+}
+
+// textSearch - Builds the $text search string; words are ORed and ranked, phrases must all match
+func (q searchQuery) textSearch() string {
+	parts := append([]string{}, q.Words...)
+	for _, phrase := range q.Phrases {
+		parts = append(parts, `"`+strings.ReplaceAll(phrase, `"`, "")+`"`)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	for _, excluded := range q.Excluded {
+		// Excluded phrases stay quoted, otherwise $text would exclude their first word and search for the rest
+		if strings.ContainsAny(excluded, " \t") {
+			excluded = `"` + strings.ReplaceAll(excluded, `"`, "") + `"`
+		}
+		parts = append(parts, "-"+excluded)
+	}
+	return strings.Join(parts, " ")
+}
+
+// filter - Builds the Mongo filter for the query, limited to the tasks matched by access.
+// The text index handles words and phrases; prefix terms, which it cannot match, become anchored regexes.
+func (q searchQuery) filter(access bson.M) bson.M {
+	conditions := []bson.M{helper.NotDeleted(access)}
+	search := q.textSearch()
+	if search != "" {
+		conditions = append(conditions, bson.M{"$text": bson.M{"$search": search}})
+	}
+	for _, prefix := range q.Prefixes {
+		conditions = append(conditions, bson.M{"title": bson.M{"$regex": `\b` + regexp.QuoteMeta(prefix), "$options": "i"}})
+	}
+	// Without a $text stage, excluded words have to be filtered out explicitly
+	if search == "" {
+		for _, excluded := range q.Excluded {
+			conditions = append(conditions, bson.M{"title": bson.M{"$not": bson.M{"$regex": excludedPattern(excluded), "$options": "i"}}})
+		}
+	}
+	return bson.M{"$and": conditions}
+}
+
+// excludedPattern - Matches an excluded word, or an excluded phrase with its words in order, as whole words
+func excludedPattern(excluded string) string {
+	words := strings.Fields(excluded)
+	for i, word := range words {
+		words[i] = regexp.QuoteMeta(word)
+	}
+	return `\b` + strings.Join(words, `\s+`) + `\b`
+}
+
+// highlight - Wraps the parts of the title matched by the query in <mark> tags, escaping everything else
+func (q searchQuery) highlight(title string) string {
+	var patterns []string
+	for _, word := range q.Words {
+		patterns = append(patterns, `\b`+regexp.QuoteMeta(word)+`\w*`)
+	}
+	for _, prefix := range q.Prefixes {
+		patterns = append(patterns, `\b`+regexp.QuoteMeta(prefix)+`\w*`)
+	}
+	for _, phrase := range q.Phrases {
+		patterns = append(patterns, regexp.QuoteMeta(phrase))
+	}
+	if len(patterns) == 0 {
+		return html.EscapeString(title)
+	}
+
+	matcher := regexp.MustCompile(`(?i)` + strings.Join(patterns, "|"))
+	ranges := matcher.FindAllStringIndex(title, -1)
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+
+	var out strings.Builder
+	last := 0
+	for _, r := range ranges {
+		if r[0] < last {
+			continue
+		}
+		out.WriteString(html.EscapeString(title[last:r[0]]))
+		out.WriteString("<mark>" + html.EscapeString(title[r[0]:r[1]]) + "</mark>")
+		last = r[1]
+	}
+	out.WriteString(html.EscapeString(title[last:]))
+	return out.String()
+}
+
+// SearchTasks - Relevance-ranked full-text search over the user's task titles.
+// Supports "exact phrases", prefix* matching and -excluded words; results carry a highlighted title.
+func SearchTasks() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		raw := strings.TrimSpace(c.Query("q"))
+		if raw == "" || len(raw) > maxSearchLength {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid search query", "q is required and must be at most "+strconv.Itoa(maxSearchLength)+" characters")
+			return
+		}
+
+		limit := defaultSearchLimit
+		if l := c.Query("limit"); l != "" {
+			parsed, err := strconv.Atoi(l)
+			if err != nil || parsed < 1 || parsed > maxSearchLimit {
+				helper.RespondWithError(c, http.StatusBadRequest, "Invalid query parameters", "limit must be between 1 and "+strconv.Itoa(maxSearchLimit))
+				return
+			}
+			limit = parsed
+		}
+
+		query := parseSearchQuery(raw)
+		if len(query.Words) == 0 && len(query.Phrases) == 0 && len(query.Prefixes) == 0 {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid search query", "The query needs at least one term that is not excluded")
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		opts := options.Find().SetLimit(int64(limit))
+		if query.textSearch() != "" {
+			score := bson.M{"$meta": "textScore"}
+			opts.SetProjection(bson.M{"score": score}).SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: -1}})
+		} else {
+			opts.SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+		}
+
+		// Search sees the same tasks as the task list: shared and assigned ones, and the whole workspace for members
+		access, err := accessibleTaskFilter(ctx, userID)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error searching tasks", err.Error())
+			return
+		}
+		cursor, err := database.GetTaskCollection().Find(ctx, query.filter(access), opts)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error searching tasks", err.Error())
+			return
+		}
+		defer cursor.Close(ctx)
+
+		results := []SearchResult{}
+		for cursor.Next(ctx) {
+			var match struct {
+				model.Task `bson:",inline"`
+				Score      float64 `bson:"score"`
+			}
+			if err := cursor.Decode(&match); err != nil {
+				helper.RespondWithError(c, http.StatusInternalServerError, "Error decoding tasks", err.Error())
+				return
+			}
+			results = append(results, SearchResult{Task: match.Task, Score: match.Score, Highlight: query.highlight(match.Title)})
+		}
+		if err := cursor.Err(); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error searching tasks", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Search results for "+username, results)
+	}
+}
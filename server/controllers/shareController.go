@@ -0,0 +1,479 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	database "task-manager/server/database"
+	helper "task-manager/server/helpers"
+	model "task-manager/server/models"
+)
+
+// strongestShare - Returns the highest role among the shares, or "" when there are none
+func strongestShare(shares []model.Share) model.ShareRole {
+	var role model.ShareRole
+	for _, share := range shares {
+		if role == "" || !role.Allows(share.Role) {
+			role = share.Role
+		}
+	}
+	return role
+}
+
+// findShares - Loads the shares the user received that match filter
+func findShares(ctx context.Context, userID string, filter bson.M) ([]model.Share, error) {
+	filter["user_id"] = userID
+	cursor, err := database.GetShareCollection().Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var shares []model.Share
+	err = cursor.All(ctx, &shares)
+	return shares, err
+}
+
+// taskRole - Returns the access the user has to a task: owner of their own tasks, otherwise the highest role
+// shared with them on the task or on its project, or "" when it is not shared with them at all.
+// Assignees who have not declined can edit the task. Outside personal workspaces the user's workspace role
+// applies to every task too: admins act as owners and members as editors, while guests rely on shares alone.
+func taskRole(ctx context.Context, userID string, task model.Task) (model.ShareRole, error) {
+	if task.UserID == userID {
+		return model.RoleOwner, nil
+	}
+	_, workspaceRole, _ := helper.WorkspaceFromContext(ctx)
+	if workspaceRole.Allows(model.WorkspaceAdmin) {
+		return model.RoleOwner, nil
+	}
+
+	resources := bson.A{bson.M{"resource": model.ShareTask, "resource_id": task.ID}}
+	if task.ProjectID != nil {
+		resources = append(resources, bson.M{"resource": model.ShareProject, "resource_id": *task.ProjectID})
+	}
+	shares, err := findShares(ctx, userID, bson.M{"$or": resources})
+	if err != nil {
+		return "", err
+	}
+	if task.IsAssignedTo(userID) || workspaceRole.Allows(model.WorkspaceMember) {
+		shares = append(shares, model.Share{Role: model.RoleEditor})
+	}
+	return strongestShare(shares), nil
+}
+
+// requireTaskRole - Ensures the user has at least need access to the task.
+// Tasks that are not shared with the user at all are reported as missing, so their existence is not revealed.
+func requireTaskRole(ctx context.Context, userID string, task model.Task, need model.ShareRole) error {
+	role, err := taskRole(ctx, userID, task)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return helper.NewRequestError(http.StatusNotFound, "Task not found", "No task found for the specified ID and user")
+	}
+	if !role.Allows(need) {
+		return helper.NewRequestError(http.StatusForbidden, "Not allowed", "The task is shared with you as "+string(role)+", which does not allow this")
+	}
+	return nil
+}
+
+// findAccessibleTask - Loads a task the user owns or that is shared with them with at least need access
+func findAccessibleTask(ctx context.Context, userID string, id primitive.ObjectID, need model.ShareRole) (model.Task, error) {
+	var task model.Task
+	err := database.GetTaskCollection().FindOne(ctx, helper.NotDeleted(helper.InWorkspace(ctx, bson.M{"_id": id}))).Decode(&task)
+	if err == mongo.ErrNoDocuments {
+		return task, helper.NewRequestError(http.StatusNotFound, "Task not found", "No task found for the specified ID and user")
+	} else if err != nil {
+		return task, err
+	}
+	return task, requireTaskRole(ctx, userID, task, need)
+}
+
+// accessibleTaskFilter - Matches the tasks of the workspace the user owns or is assigned along with the ones
+// shared with them, directly or through their project. Members see all of the workspace's tasks.
+func accessibleTaskFilter(ctx context.Context, userID string) (bson.M, error) {
+	if requireWorkspaceRole(ctx, model.WorkspaceMember) == nil {
+		return helper.InWorkspace(ctx, bson.M{}), nil
+	}
+
+	shares, err := findShares(ctx, userID, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	var taskIDs, projectIDs []primitive.ObjectID
+	for _, share := range shares {
+		if share.Resource == model.ShareProject {
+			projectIDs = append(projectIDs, share.ResourceID)
+		} else {
+			taskIDs = append(taskIDs, share.ResourceID)
+		}
+	}
+
+	access := bson.A{bson.M{"user_id": userID}, assignedToFilter(userID)}
+	if len(taskIDs) > 0 {
+		access = append(access, bson.M{"_id": bson.M{"$in": taskIDs}})
+	}
+	if len(projectIDs) > 0 {
+		access = append(access, bson.M{"project_id": bson.M{"$in": projectIDs}})
+	}
+	return helper.InWorkspace(ctx, bson.M{"$or": access}), nil
+}
+
+// findShareTarget - Loads the task or project whose shares are being managed and returns its owner.
+// Managing shares takes owner access.
+func findShareTarget(ctx context.Context, userID string, resource model.ShareResource, id primitive.ObjectID) (string, error) {
+	if resource == model.ShareTask {
+		task, err := findAccessibleTask(ctx, userID, id, model.RoleOwner)
+		return task.UserID, err
+	}
+
+	var project model.Project
+	err := database.GetProjectCollection().FindOne(ctx, helper.InWorkspace(ctx, bson.M{"_id": id})).Decode(&project)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return "", err
+	}
+	role := model.RoleOwner
+	if err == nil && project.UserID != userID {
+		shares, err := findShares(ctx, userID, bson.M{"resource": model.ShareProject, "resource_id": id})
+		if err != nil {
+			return "", err
+		}
+		role = strongestShare(shares)
+	}
+	if project.ID.IsZero() || role == "" {
+		return "", helper.NewRequestError(http.StatusNotFound, "Project not found", "No project found for the specified ID and user")
+	}
+	if !role.Allows(model.RoleOwner) {
+		return "", helper.NewRequestError(http.StatusForbidden, "Not allowed", "Only owners can manage who a project is shared with")
+	}
+	if project.IsFolder {
+		return "", helper.NewRequestError(http.StatusBadRequest, "Invalid project", "Folders cannot be shared, share the projects inside them instead")
+	}
+	return project.UserID, nil
+}
+
+// findUser - Looks up another user by user ID or, when that is empty, by username
+func findUser(ctx context.Context, userID string, username string) (model.User, error) {
+	filter := bson.M{"user_id": userID}
+	if userID == "" {
+		filter = bson.M{"username": username}
+	}
+
+	var user model.User
+	err := database.GetUserCollection().FindOne(ctx, filter).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return user, helper.NewRequestError(http.StatusNotFound, "User not found", "No user found for the specified ID or username")
+	}
+	return user, err
+}
+
+// GetShares - Lists who a task or project is shared with
+func GetShares(resource model.ShareResource) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		if _, err := findShareTarget(ctx, userID, resource, id); err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching "+string(resource))
+			return
+		}
+
+		opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+		cursor, err := database.GetShareCollection().Find(ctx, bson.M{"resource": resource, "resource_id": id}, opts)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error fetching shares", err.Error())
+			return
+		}
+		defer cursor.Close(ctx)
+
+		shares := []model.Share{}
+		if err := cursor.All(ctx, &shares); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error decoding shares", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Shares for "+username, shares)
+	}
+}
+
+// GrantShare - Shares a task or project with another user, identified by user_id or username
+func GrantShare(resource model.ShareResource) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		var request struct {
+			UserID   string          `json:"user_id"`
+			Username string          `json:"username"`
+			Role     model.ShareRole `json:"role" validate:"required,oneof=viewer editor owner"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid JSON input", err.Error())
+			return
+		}
+		if err := validate.Struct(request); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Validation error", err.Error())
+			return
+		}
+		if (request.UserID == "") == (request.Username == "") {
+			helper.RespondWithError(c, http.StatusBadRequest, "Validation error", "Give exactly one of user_id or username")
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		ownerID, err := findShareTarget(ctx, userID, resource, id)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching "+string(resource))
+			return
+		}
+		grantee, err := findUser(ctx, request.UserID, request.Username)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching user")
+			return
+		}
+		if *grantee.UserID == ownerID {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid share", "The owner already has full access")
+			return
+		}
+		workspaceID, _, _ := helper.WorkspaceFromContext(ctx)
+		if err := checkWorkspaceMember(ctx, workspaceID, *grantee.UserID); err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching member")
+			return
+		}
+
+		now := time.Now().UTC()
+		share := model.Share{
+			ID:          primitive.NewObjectID(),
+			WorkspaceID: workspaceID,
+			Resource:    resource,
+			ResourceID:  id,
+			OwnerID:     ownerID,
+			UserID:      *grantee.UserID,
+			Username:    *grantee.Username,
+			Role:        request.Role,
+			GrantedBy:   userID,
+			Created:     now,
+			Updated:     now,
+		}
+		if _, err := database.GetShareCollection().InsertOne(ctx, share); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				helper.RespondWithError(c, http.StatusConflict, "Already shared", "The "+string(resource)+" is already shared with "+share.Username+", change their role instead")
+				return
+			}
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error sharing "+string(resource), err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusCreated, "Shared successfully by "+username, share)
+	}
+}
+
+// UpdateShare - Changes the role of a user a task or project is shared with
+func UpdateShare(resource model.ShareResource) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		var request struct {
+			Role model.ShareRole `json:"role" validate:"required,oneof=viewer editor owner"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid JSON input", err.Error())
+			return
+		}
+		if err := validate.Struct(request); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Validation error", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		if _, err := findShareTarget(ctx, userID, resource, id); err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching "+string(resource))
+			return
+		}
+
+		var share model.Share
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		err = database.GetShareCollection().FindOneAndUpdate(ctx,
+			bson.M{"resource": resource, "resource_id": id, "user_id": c.Param("user_id")},
+			bson.M{"$set": bson.M{"role": request.Role, "updated_at": time.Now().UTC()}},
+			opts,
+		).Decode(&share)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				helper.RespondWithError(c, http.StatusNotFound, "Share not found", "The "+string(resource)+" is not shared with that user")
+				return
+			}
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error updating share", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Share updated successfully by "+username, share)
+	}
+}
+
+// RevokeShare - Stops sharing a task or project with a user. Users can also remove their own access.
+func RevokeShare(resource model.ShareResource) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+		granteeID := c.Param("user_id")
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		if granteeID != userID {
+			if _, err := findShareTarget(ctx, userID, resource, id); err != nil {
+				helper.RespondWithRequestError(c, err, "Error fetching "+string(resource))
+				return
+			}
+		}
+
+		result, err := database.GetShareCollection().DeleteOne(ctx, bson.M{"resource": resource, "resource_id": id, "user_id": granteeID})
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error revoking share", err.Error())
+			return
+		}
+		if result.DeletedCount == 0 {
+			helper.RespondWithError(c, http.StatusNotFound, "Share not found", "The "+string(resource)+" is not shared with that user")
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Share revoked successfully", nil)
+	}
+}
+
+// GetSharedWithMe - Lists the tasks and projects other users have shared with the user in any workspace, newest share first.
+// Each share names the workspace its requests go to. Shares of tasks that are in the trash are left out.
+func GetSharedWithMe() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		limit, err := parsePageSize(c)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(int64(limit))
+		cursor, err := database.GetShareCollection().Find(ctx, bson.M{"user_id": userID}, opts)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error fetching shares", err.Error())
+			return
+		}
+		var shares []model.Share
+		if err := cursor.All(ctx, &shares); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error decoding shares", err.Error())
+			return
+		}
+
+		var taskIDs, projectIDs []primitive.ObjectID
+		for _, share := range shares {
+			if share.Resource == model.ShareProject {
+				projectIDs = append(projectIDs, share.ResourceID)
+			} else {
+				taskIDs = append(taskIDs, share.ResourceID)
+			}
+		}
+
+		// Load everything shared in two queries rather than one per share
+		tasks := map[primitive.ObjectID]*model.Task{}
+		if len(taskIDs) > 0 {
+			cursor, err := database.GetTaskCollection().Find(ctx, helper.NotDeleted(bson.M{"_id": bson.M{"$in": taskIDs}}))
+			if err != nil {
+				helper.RespondWithError(c, http.StatusInternalServerError, "Error fetching shared tasks", err.Error())
+				return
+			}
+			var found []model.Task
+			if err := cursor.All(ctx, &found); err != nil {
+				helper.RespondWithError(c, http.StatusInternalServerError, "Error decoding shared tasks", err.Error())
+				return
+			}
+			for i := range found {
+				tasks[found[i].ID] = &found[i]
+			}
+		}
+		projects := map[primitive.ObjectID]*model.Project{}
+		if len(projectIDs) > 0 {
+			cursor, err := database.GetProjectCollection().Find(ctx, bson.M{"_id": bson.M{"$in": projectIDs}})
+			if err != nil {
+				helper.RespondWithError(c, http.StatusInternalServerError, "Error fetching shared projects", err.Error())
+				return
+			}
+			var found []model.Project
+			if err := cursor.All(ctx, &found); err != nil {
+				helper.RespondWithError(c, http.StatusInternalServerError, "Error decoding shared projects", err.Error())
+				return
+			}
+			for i := range found {
+				projects[found[i].ID] = &found[i]
+			}
+		}
+
+		items := []model.SharedItem{}
+		for _, share := range shares {
+			item := model.SharedItem{Share: share, Task: tasks[share.ResourceID], Project: projects[share.ResourceID]}
+			if item.Task != nil || item.Project != nil {
+				items = append(items, item)
+			}
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Shared with "+username, items)
+	}
+}
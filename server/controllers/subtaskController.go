@@ -0,0 +1,368 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	database "task-manager/server/database"
+	helper "task-manager/server/helpers"
+	model "task-manager/server/models"
+)
+
+// maxTaskDepth - How deep tasks may be nested, which also bounds the ancestor walk in cycle checks
+const maxTaskDepth = 20
+
+// findTask - Loads a task owned by the user
+func findTask(ctx context.Context, userID string, id primitive.ObjectID) (model.Task, error) {
+	var task model.Task
+	err := database.GetTaskCollection().FindOne(ctx, helper.NotDeleted(helper.InWorkspace(ctx, bson.M{"_id": id, "user_id": userID}))).Decode(&task)
+	if err == mongo.ErrNoDocuments {
+		return task, helper.NewRequestError(http.StatusNotFound, "Task not found", "No task found for the specified ID and user")
+	}
+	return task, err
+}
+
+// checkTaskParent - Ensures parentID is a task of the user that taskID can be nested under
+// without becoming its own ancestor or its deepest subtask exceeding maxTaskDepth
+func checkTaskParent(ctx context.Context, userID string, taskID primitive.ObjectID, parentID primitive.ObjectID) (model.Task, error) {
+	parent, err := findTask(ctx, userID, parentID)
+	var reqErr *helper.RequestError
+	if errors.As(err, &reqErr) {
+		return parent, helper.NewRequestError(http.StatusBadRequest, "Invalid parent", "Parent task not found")
+	} else if err != nil {
+		return parent, err
+	}
+
+	tooDeep := helper.NewRequestError(http.StatusBadRequest, "Invalid parent", "Tasks cannot be nested this deeply")
+	ancestor := parent
+	depth := 1
+	for ; ; depth++ {
+		if ancestor.ID == taskID {
+			return parent, helper.NewRequestError(http.StatusBadRequest, "Invalid parent", "A task cannot be nested under itself or one of its subtasks")
+		}
+		if depth >= maxTaskDepth {
+			return parent, tooDeep
+		}
+		if ancestor.ParentID == nil {
+			break
+		}
+		if ancestor, err = findTask(ctx, userID, *ancestor.ParentID); err != nil {
+			return parent, err
+		}
+	}
+
+	// The task's own subtasks move along with it, so the deepest of them must fit too
+	height, err := subtreeHeight(ctx, userID, taskID)
+	if err != nil {
+		return parent, err
+	}
+	if depth+height >= maxTaskDepth {
+		return parent, tooDeep
+	}
+	return parent, nil
+}
+
+// subtreeHeight - Returns how many levels of subtasks are nested under a task, 0 when it has none
+// or does not exist yet
+func subtreeHeight(ctx context.Context, userID string, taskID primitive.ObjectID) (int, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: helper.InWorkspace(ctx, bson.M{"_id": taskID, "user_id": userID})}},
+		{{Key: "$graphLookup", Value: bson.M{
+			"from":                    "tasks",
+			"startWith":               "$_id",
+			"connectFromField":        "_id",
+			"connectToField":          "parent_id",
+			"as":                      "descendants",
+			"maxDepth":                maxTaskDepth,
+			"depthField":              "depth",
+			"restrictSearchWithMatch": helper.InWorkspace(ctx, helper.NotDeleted(bson.M{"user_id": userID})),
+		}}},
+		{{Key: "$project", Value: bson.M{"deepest": bson.M{"$max": "$descendants.depth"}}}},
+	}
+
+	cursor, err := database.GetTaskCollection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Deepest *int `bson:"deepest"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return 0, err
+	}
+	if len(results) == 0 || results[0].Deepest == nil {
+		return 0, nil
+	}
+	// depthField counts the direct subtasks as depth 0
+	return *results[0].Deepest + 1, nil
+}
+
+// findDescendants - Loads every task nested under the root, at any depth, leaving out the trash
+func findDescendants(ctx context.Context, userID string, rootID primitive.ObjectID) ([]model.Task, error) {
+	live := helper.NotDeleted(bson.M{"user_id": userID})
+	return lookupDescendants(ctx, bson.M{"_id": rootID, "user_id": userID, "deleted_at": nil}, live)
+}
+
+// lookupDescendants - Loads the tasks nested under the task matched by root, following only tasks matched by restrict
+func lookupDescendants(ctx context.Context, root bson.M, restrict bson.M) ([]model.Task, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: helper.InWorkspace(ctx, root)}},
+		{{Key: "$graphLookup", Value: bson.M{
+			"from":                    "tasks",
+			"startWith":               "$_id",
+			"connectFromField":        "_id",
+			"connectToField":          "parent_id",
+			"as":                      "descendants",
+			"maxDepth":                maxTaskDepth,
+			"restrictSearchWithMatch": helper.InWorkspace(ctx, restrict),
+		}}},
+		{{Key: "$project", Value: bson.M{"descendants": 1}}},
+	}
+
+	cursor, err := database.GetTaskCollection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Descendants []model.Task `bson:"descendants"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, helper.NewRequestError(http.StatusNotFound, "Task not found", "No task found for the specified ID and user")
+	}
+	return results[0].Descendants, nil
+}
+
+// childTaskIDs - Returns the IDs of the direct subtasks of a task, leaving out the trash
+func childTaskIDs(ctx context.Context, userID string, parentID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+	cursor, err := database.GetTaskCollection().Find(ctx, helper.NotDeleted(helper.InWorkspace(ctx, bson.M{"user_id": userID, "parent_id": parentID})), opts)
+	if err != nil {
+		return nil, err
+	}
+	var children []model.Task
+	if err := cursor.All(ctx, &children); err != nil {
+		return nil, err
+	}
+	return taskIDs(children), nil
+}
+
+// taskIDs - Collects the IDs of the given tasks
+func taskIDs(tasks []model.Task) []primitive.ObjectID {
+	ids := make([]primitive.ObjectID, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
+
+// buildTaskTree - Attaches descendants to the root as nested children, down to maxDepth levels,
+// and rolls up the status and due dates of each node's whole subtree
+func buildTaskTree(root *model.Task, descendants []model.Task, maxDepth int) {
+	byParent := map[primitive.ObjectID][]model.Task{}
+	for _, task := range descendants {
+		if task.ParentID != nil {
+			byParent[*task.ParentID] = append(byParent[*task.ParentID], task)
+		}
+	}
+
+	var attach func(node *model.Task, depth int) model.SubtaskRollup
+	attach = func(node *model.Task, depth int) model.SubtaskRollup {
+		rollup := model.SubtaskRollup{}
+		if depth > maxTaskDepth {
+			return rollup
+		}
+
+		children := byParent[node.ID]
+		for i := range children {
+			child := &children[i]
+			childRollup := attach(child, depth+1)
+
+			rollup.Total += 1 + childRollup.Total
+			rollup.Done += childRollup.Done
+			if child.Status.IsClosed() {
+				rollup.Done++
+			}
+			rollup.NextDueAt = earliest(rollup.NextDueAt, childRollup.NextDueAt)
+			if !child.Status.IsClosed() {
+				rollup.NextDueAt = earliest(rollup.NextDueAt, child.DueAt)
+			}
+		}
+
+		if len(children) > 0 {
+			node.Subtasks = &rollup
+		}
+		if depth < maxDepth {
+			node.Children = children
+		}
+		return rollup
+	}
+	attach(root, 0)
+}
+
+// earliest - Returns the earlier of two optional timestamps
+func earliest(a, b *time.Time) *time.Time {
+	if a == nil || (b != nil && b.Before(*a)) {
+		return b
+	}
+	return a
+}
+
+// cascadeStatusToDescendants - When a parent is closed, moves its open descendants to the same status.
+// Descendants the workflow does not allow to make that move (e.g. blocked tasks being completed) are left as they are.
+func cascadeStatusToDescendants(ctx context.Context, userID string, parent model.Task, now time.Time) error {
+	if !parent.Status.IsClosed() {
+		return nil
+	}
+
+	descendants, err := findDescendants(ctx, userID, parent.ID)
+	if err != nil || len(descendants) == 0 {
+		return err
+	}
+
+	var movable []model.TaskStatus
+	for status := range model.TaskTransitions {
+		if status != parent.Status && !status.IsClosed() && status.CanTransitionTo(parent.Status) {
+			movable = append(movable, status)
+		}
+	}
+
+	update := bson.M{"status": parent.Status, "updated_at": now}
+	if parent.Status == model.StatusDone {
+		update["completed_at"] = now
+	}
+
+	ids := taskIDs(descendants)
+	result, err := database.GetTaskCollection().UpdateMany(ctx,
+		helper.InWorkspace(ctx, bson.M{"_id": bson.M{"$in": ids}, "user_id": userID, "status": bson.M{"$in": movable}}),
+		bson.M{"$set": update},
+	)
+	if err != nil || result.ModifiedCount == 0 {
+		return err
+	}
+	// Tasks waiting for the descendants that were just closed may now be ready to start
+	return syncDependents(ctx, ids)
+}
+
+// GetTaskSubtree - Retrieves a task with all of its subtasks nested beneath it
+func GetTaskSubtree() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		task, err := findTask(ctx, userID, id)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching task")
+			return
+		}
+
+		descendants, err := findDescendants(ctx, userID, id)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching subtasks")
+			return
+		}
+		buildTaskTree(&task, descendants, maxTaskDepth)
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Task tree for "+username, task)
+	}
+}
+
+// MoveTask - Re-parents a task under another task, or makes it top-level when parent_id is null
+func MoveTask() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		var request struct {
+			ParentID model.Nullable[primitive.ObjectID] `json:"parent_id"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid JSON input", err.Error())
+			return
+		}
+		if !request.ParentID.Set {
+			helper.RespondWithError(c, http.StatusBadRequest, "Validation error", "parent_id is required, use null to move the task to the top level")
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		update := bson.M{"updated_at": time.Now().UTC()}
+		changes := bson.M{"$set": update}
+		if request.ParentID.Value == nil {
+			changes["$unset"] = bson.M{"parent_id": ""}
+		} else {
+			if _, err := checkTaskParent(ctx, userID, id, *request.ParentID.Value); err != nil {
+				helper.RespondWithRequestError(c, err, "Error checking parent task")
+				return
+			}
+			update["parent_id"] = *request.ParentID.Value
+		}
+
+		// The task goes to the end of its new parent's subtasks
+		current, err := findTask(ctx, userID, id)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching task")
+			return
+		}
+		if update["rank"], err = rankAtEnd(ctx, userID, current.ProjectID, request.ParentID.Value); err != nil {
+			helper.RespondWithRequestError(c, err, "Error ranking task")
+			return
+		}
+
+		result, err := database.GetTaskCollection().UpdateOne(ctx, helper.NotDeleted(helper.InWorkspace(ctx, bson.M{"_id": id, "user_id": userID})), changes)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error moving task", err.Error())
+			return
+		}
+		if result.MatchedCount == 0 {
+			helper.RespondWithError(c, http.StatusNotFound, "Task not found", "No task found for the specified ID and user "+username)
+			return
+		}
+
+		task, err := findTask(ctx, userID, id)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching task")
+			return
+		}
+		logRevision(ctx, userID, username, model.RevisionUpdate, &current, task)
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Task moved successfully for "+username, task)
+	}
+}
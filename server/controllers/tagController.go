@@ -0,0 +1,338 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	database "task-manager/server/database"
+	helper "task-manager/server/helpers"
+	model "task-manager/server/models"
+)
+
+// normalizeTag - Tags are matched case-insensitively, so they are stored trimmed and lower case
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// normalizeTags - Normalizes a list of tags, dropping blanks and duplicates while keeping order
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// registerTags - Adds any tags not yet in the user's catalogue
+func registerTags(ctx context.Context, userID string, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	writes := make([]mongo.WriteModel, 0, len(tags))
+	for _, tag := range tags {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"user_id": userID, "name": tag}).
+			SetUpdate(bson.M{"$setOnInsert": bson.M{"created_at": now, "updated_at": time.Time{}}}).
+			SetUpsert(true))
+	}
+
+	_, err := database.GetTagCollection().BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// replaceTagOnTasks - Renames a tag on every task of the user, dropping the duplicate if the task already had the new name
+func replaceTagOnTasks(ctx context.Context, userID string, from string, to string) error {
+	renamed := bson.M{"$map": bson.M{
+		"input": "$tags",
+		"in":    bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$$this", from}}, to, "$$this"}},
+	}}
+	deduplicated := bson.M{"$reduce": bson.M{
+		"input":        renamed,
+		"initialValue": bson.A{},
+		"in": bson.M{"$cond": bson.A{
+			bson.M{"$in": bson.A{"$$this", "$$value"}},
+			"$$value",
+			bson.M{"$concatArrays": bson.A{"$$value", bson.A{"$$this"}}},
+		}},
+	}}
+
+	// The tag catalogue is the user's own across workspaces, so the rename reaches their tasks in all of them.
+	// Nobody else's tasks are touched.
+	_, err := database.GetTaskCollection().UpdateMany(ctx,
+		bson.M{"user_id": userID, "tags": from},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{"tags": deduplicated, "updated_at": "$$NOW"}}}},
+	)
+	return err
+}
+
+// findTag - Loads a tag from the user's catalogue
+func findTag(ctx context.Context, userID string, id primitive.ObjectID) (model.Tag, error) {
+	var tag model.Tag
+	err := database.GetTagCollection().FindOne(ctx, bson.M{"_id": id, "user_id": userID}).Decode(&tag)
+	if err == mongo.ErrNoDocuments {
+		return tag, helper.NewRequestError(http.StatusNotFound, "Tag not found", "No tag found for the specified ID and user")
+	}
+	return tag, err
+}
+
+// GetTags - Retrieves the user's tag catalogue
+func GetTags() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+		cursor, err := database.GetTagCollection().Find(ctx, bson.M{"user_id": userID}, opts)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error fetching tags", err.Error())
+			return
+		}
+		defer cursor.Close(ctx)
+
+		tags := []model.Tag{}
+		if err = cursor.All(ctx, &tags); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error decoding tags", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Tags for "+username, tags)
+	}
+}
+
+// PostTag - Adds a tag to the user's catalogue
+func PostTag() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		var newTag model.Tag
+		if err := c.ShouldBindJSON(&newTag); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid JSON input", err.Error())
+			return
+		}
+
+		newTag.ID = primitive.NewObjectID()
+		newTag.UserID = userID
+		newTag.Name = normalizeTag(newTag.Name)
+		newTag.Created = time.Now().UTC()
+		newTag.Updated = time.Time{}
+
+		if err := validate.Struct(newTag); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Validation error", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		if _, err := database.GetTagCollection().InsertOne(ctx, newTag); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				helper.RespondWithError(c, http.StatusConflict, "Tag already exists", "A tag named "+newTag.Name+" already exists")
+				return
+			}
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error inserting tag", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusCreated, "Tag created successfully", newTag)
+	}
+}
+
+// UpdateTag - Changes a tag's color or renames it, rewriting every task that carries it
+func UpdateTag() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		var updatedFields struct {
+			Name  *string `json:"name" validate:"omitempty,min=1,max=30"`
+			Color *string `json:"color" validate:"omitempty,hexcolor"`
+		}
+
+		if err := c.ShouldBindJSON(&updatedFields); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid JSON input", err.Error())
+			return
+		}
+
+		if updatedFields.Name != nil {
+			*updatedFields.Name = normalizeTag(*updatedFields.Name)
+		}
+
+		if err := validate.Struct(updatedFields); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Validation error", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		tag, err := findTag(ctx, userID, id)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching tag")
+			return
+		}
+
+		oldName := tag.Name
+		update := bson.M{"updated_at": time.Now().UTC()}
+		if updatedFields.Color != nil {
+			update["color"] = *updatedFields.Color
+		}
+
+		renamed := updatedFields.Name != nil && *updatedFields.Name != oldName
+		if renamed {
+			update["name"] = *updatedFields.Name
+		}
+
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		err = database.GetTagCollection().FindOneAndUpdate(ctx, bson.M{"_id": id, "user_id": userID}, bson.M{"$set": update}, opts).Decode(&tag)
+		if err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				helper.RespondWithError(c, http.StatusConflict, "Tag already exists", "Merge into the existing tag instead of renaming")
+				return
+			}
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error updating tag", err.Error())
+			return
+		}
+
+		if renamed {
+			if err := replaceTagOnTasks(ctx, userID, oldName, tag.Name); err != nil {
+				helper.RespondWithError(c, http.StatusInternalServerError, "Error renaming tag on tasks", err.Error())
+				return
+			}
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Tag updated successfully", tag)
+	}
+}
+
+// MergeTag - Folds a tag into another one, retagging its tasks and removing it from the catalogue
+func MergeTag() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		var request struct {
+			Into primitive.ObjectID `json:"into" validate:"required"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid JSON input", err.Error())
+			return
+		}
+		if request.Into.IsZero() || request.Into == id {
+			helper.RespondWithError(c, http.StatusBadRequest, "Validation error", "into must be the ID of a different tag")
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		source, err := findTag(ctx, userID, id)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching tag")
+			return
+		}
+		target, err := findTag(ctx, userID, request.Into)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching tag")
+			return
+		}
+
+		if err := replaceTagOnTasks(ctx, userID, source.Name, target.Name); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error merging tag on tasks", err.Error())
+			return
+		}
+
+		if _, err := database.GetTagCollection().DeleteOne(ctx, bson.M{"_id": source.ID, "user_id": userID}); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error deleting merged tag", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Tag "+source.Name+" merged into "+target.Name, target)
+	}
+}
+
+// DeleteTag - Removes a tag from the catalogue and from every task that carries it
+func DeleteTag() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		tag, err := findTag(ctx, userID, id)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching tag")
+			return
+		}
+
+		// Like renames, deleting a catalogue tag clears it from the user's tasks in every workspace
+		_, err = database.GetTaskCollection().UpdateMany(ctx,
+			bson.M{"user_id": userID, "tags": tag.Name},
+			bson.M{"$pull": bson.M{"tags": tag.Name}, "$set": bson.M{"updated_at": time.Now().UTC()}},
+		)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error removing tag from tasks", err.Error())
+			return
+		}
+
+		if _, err := database.GetTagCollection().DeleteOne(ctx, bson.M{"_id": id, "user_id": userID}); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error deleting tag", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Tag deleted successfully", nil)
+	}
+}
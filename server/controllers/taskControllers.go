@@ -2,6 +2,8 @@ package controller
 
 import (
 	"context"
+	"fmt"
+	"log"
 	"net/http"
 	"time"
 
@@ -16,9 +18,10 @@ import (
 	model "task-manager/server/models"
 )
 
-// Helper function to handle context setup -- keepng it here for ease
-func getContextWithTimeout() (context.Context, context.CancelFunc) {
-	return context.WithTimeout(context.Background(), 10*time.Second)
+// Helper function to handle context setup -- keepng it here for ease.
+// The context carries the request's workspace but not its cancellation, so a client going away mid-write does not abort it.
+func getContextWithTimeout(c *gin.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(c.Request.Context()), 10*time.Second)
 }
 
 // HealthCheck - Health endpoint, returns a simple status response
@@ -29,7 +32,9 @@ func HealthCheck() gin.HandlerFunc {
 	}
 }
 
-// GetTasks - Retrieves all tasks
+// GetTasks - Retrieves a page of tasks, optionally filtered by project, tags and due and start dates,
+// and sorted by the keys given in ?sort=. ?view= lists a saved filter or smart list.
+// Further pages are fetched with the returned cursors.
 func GetTasks() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, username, valid := helper.GetUserDetails(c)
@@ -38,37 +43,86 @@ func GetTasks() gin.HandlerFunc {
 			return
 		}
 
-		ctx, cancel := getContextWithTimeout()
+		ctx, cancel := getContextWithTimeout(c)
 		defer cancel()
 
-		taskCollection := database.GetTaskCollection()
-		filter := bson.M{"user_id": userID}
+		access, err := accessibleTaskFilter(ctx, userID)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error fetching shares", err.Error())
+			return
+		}
 
-		opts := options.Find().SetSort(bson.D{{Key: "created", Value: -1}})
+		filter, err := buildTaskFilter(c, userID, access)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+			return
+		}
 
-		cursor, err := taskCollection.Find(ctx, filter, opts)
+		// view=<saved filter ID or smart list key> narrows the listing to the saved query and defaults to its sort
+		rawSort := c.Query("sort")
+		if view := c.Query("view"); view != "" {
+			savedFilter, err := findSavedFilter(ctx, userID, view)
+			if err != nil {
+				helper.RespondWithRequestError(c, err, "Error fetching saved filter")
+				return
+			}
+			viewFilter, err := parseTaskFilter(savedFilter.Query, time.Now().UTC())
+			if err != nil {
+				helper.RespondWithError(c, http.StatusBadRequest, "Invalid saved filter", err.Error())
+				return
+			}
+			if len(viewFilter) > 0 {
+				filter = bson.M{"$and": bson.A{filter, viewFilter}}
+			}
+			if rawSort == "" {
+				rawSort = savedFilter.Sort
+			}
+		}
+
+		sort, err := parseTaskSort(rawSort)
 		if err != nil {
-			helper.RespondWithError(c, http.StatusInternalServerError, "Error fetching tasks", err.Error())
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
 			return
 		}
-		defer cursor.Close(ctx)
 
-		var tasks []model.Task
-		if err = cursor.All(ctx, &tasks); err != nil {
-			helper.RespondWithError(c, http.StatusInternalServerError, "Error decoding tasks", err.Error())
+		limit, err := parsePageSize(c)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
 			return
 		}
 
-		if len(tasks) == 0 {
-			helper.RespondWithSuccess(c, http.StatusOK, "No tasks found for "+username, []model.Task{})
+		countTotal, err := parseBoolParam(c, "count")
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
 			return
 		}
 
-		helper.RespondWithSuccess(c, http.StatusOK, "Tasks for "+username, tasks)
+		taskCollection := database.GetTaskCollection()
+		page, err := findTaskPage(ctx, taskCollection, filter, sort, limit, c.Query("cursor"))
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching tasks")
+			return
+		}
+
+		if countTotal {
+			total, err := taskCollection.CountDocuments(ctx, filter)
+			if err != nil {
+				helper.RespondWithError(c, http.StatusInternalServerError, "Error counting tasks", err.Error())
+				return
+			}
+			page.Total = &total
+		}
+
+		if len(page.Tasks) == 0 {
+			helper.RespondWithSuccess(c, http.StatusOK, "No tasks found for "+username, page)
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Tasks for "+username, page)
 	}
 }
 
-// GetTaskByID - Retrieves a single task by its ID
+// GetTaskByID - Retrieves a single task by its ID. With ?include=children its direct subtasks are attached.
 func GetTaskByID() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, username, valid := helper.GetUserDetails(c)
@@ -89,21 +143,22 @@ func GetTaskByID() gin.HandlerFunc {
 			return
 		}
 
-		ctx, cancel := getContextWithTimeout()
+		ctx, cancel := getContextWithTimeout(c)
 		defer cancel()
 
-		taskCollection := database.GetTaskCollection()
-		filter := bson.M{"_id": objId, "user_id": userID}
-
-		var task model.Task
-		err = taskCollection.FindOne(ctx, filter).Decode(&task)
+		task, err := findAccessibleTask(ctx, userID, objId, model.RoleViewer)
 		if err != nil {
-			if err == mongo.ErrNoDocuments {
-				helper.RespondWithError(c, http.StatusNotFound, "Task not found", "No task found for the specified ID and user "+username)
+			helper.RespondWithRequestError(c, err, "Error fetching task")
+			return
+		}
+
+		if c.Query("include") == "children" {
+			descendants, err := findDescendants(ctx, task.UserID, task.ID)
+			if err != nil {
+				helper.RespondWithRequestError(c, err, "Error fetching subtasks")
 				return
 			}
-			helper.RespondWithError(c, http.StatusInternalServerError, "Error fetching task", err.Error())
-			return
+			buildTaskTree(&task, descendants, 1)
 		}
 
 		helper.RespondWithSuccess(c, http.StatusOK, "Task for "+username, task)
@@ -125,26 +180,119 @@ func PostTask() gin.HandlerFunc {
 			return
 		}
 
-		newTask.ID = primitive.NewObjectID()
-		newTask.UserID = userID
-		newTask.Username = username
-		newTask.Created = time.Now().UTC()
-		newTask.Updated = time.Time{}
-		newTask.Status = false
-
-		if err := validate.Struct(newTask); err != nil {
-			helper.RespondWithError(c, http.StatusBadRequest, "Validation error", err.Error())
+		task, err := createTask(c.Request.Context(), userID, username, newTask)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error creating task")
 			return
 		}
 
-		collection := database.GetTaskCollection()
-		if _, err := collection.InsertOne(c.Request.Context(), newTask); err != nil {
-			helper.RespondWithError(c, http.StatusInternalServerError, "Error inserting task", err.Error())
-			return
+		helper.RespondWithSuccess(c, http.StatusCreated, "Task created successfully", task)
+	}
+}
+
+// createTask - Validates a new task for the user and inserts it, filling in its ID, owner and timestamps
+func createTask(ctx context.Context, userID string, username string, newTask model.Task) (model.Task, error) {
+	if newTask.DueAt != nil {
+		*newTask.DueAt = newTask.DueAt.UTC()
+	}
+	if newTask.StartAt != nil {
+		*newTask.StartAt = newTask.StartAt.UTC()
+	}
+
+	newTask.Tags = normalizeTags(newTask.Tags)
+	newTask.Children = nil
+	newTask.Subtasks = nil
+	newTask.Checklist = normalizeChecklist(newTask.Checklist)
+
+	if err := requireWorkspaceRole(ctx, model.WorkspaceMember); err != nil {
+		return newTask, err
+	}
+
+	newTask.ID = primitive.NewObjectID()
+	newTask.WorkspaceID, _, _ = helper.WorkspaceFromContext(ctx)
+	newTask.UserID = userID
+	newTask.Username = username
+	newTask.Created = time.Now().UTC()
+	newTask.Updated = time.Time{}
+	newTask.Status = model.StatusTodo
+	newTask.CompletedAt = nil
+	newTask.DeletedAt = nil
+
+	if err := validate.Struct(newTask); err != nil {
+		return newTask, helper.NewRequestError(http.StatusBadRequest, "Validation error", err.Error())
+	}
+
+	if err := validateTaskDates(newTask.StartAt, newTask.DueAt); err != nil {
+		return newTask, helper.NewRequestError(http.StatusBadRequest, "Validation error", err.Error())
+	}
+
+	newTask.NextOccurrenceID = nil
+	newTask.CommentCount = 0
+	newTask.AssigneeID = nil
+	newTask.AssignmentStatus = ""
+	newTask.AssignedBy = ""
+	newTask.AssignedAt = nil
+	newTask.BlockedBy = nil
+	newTask.ResumeStatus = ""
+	newTask.Attachments = nil
+	if newTask.Recurrence != nil {
+		if err := prepareRecurrence(newTask.Recurrence, newTask.ID, newTask.DueAt, nil); err != nil {
+			return newTask, err
 		}
+	}
+
+	if newTask.ParentID != nil {
+		parent, err := checkTaskParent(ctx, userID, newTask.ID, *newTask.ParentID)
+		if err != nil {
+			return newTask, err
+		}
+		// Subtasks live in their parent's project unless told otherwise
+		if newTask.ProjectID == nil {
+			newTask.ProjectID = parent.ProjectID
+		}
+	}
 
-		helper.RespondWithSuccess(c, http.StatusCreated, "Task created successfully", newTask)
+	if newTask.ProjectID != nil {
+		if err := checkTaskProject(ctx, userID, *newTask.ProjectID); err != nil {
+			return newTask, err
+		}
 	}
+
+	rank, err := rankAtEnd(ctx, userID, newTask.ProjectID, newTask.ParentID)
+	if err != nil {
+		return newTask, err
+	}
+	newTask.Rank = rank
+
+	if err := registerTags(ctx, userID, newTask.Tags); err != nil {
+		return newTask, fmt.Errorf("registering tags: %w", err)
+	}
+
+	if _, err := database.GetTaskCollection().InsertOne(ctx, newTask); err != nil {
+		return newTask, fmt.Errorf("inserting task: %w", err)
+	}
+
+	logRevision(ctx, userID, username, model.RevisionCreate, nil, newTask)
+	logOperation(ctx, model.Operation{UserID: userID, Action: model.OperationCreate, TaskID: &newTask.ID, Stamp: newTask.Updated})
+	if err := helper.ScheduleReminders(ctx, newTask); err != nil {
+		log.Printf("Error scheduling reminders for task %s: %v", newTask.ID.Hex(), err)
+	}
+	return newTask, nil
+}
+
+// taskChanges - The fields UpdateTask can change. Fields left out of the request are not touched,
+// and the Nullable ones can be cleared with an explicit null.
+type taskChanges struct {
+	Title                 *string                            `json:"title" validate:"omitempty,min=1,max=140"`
+	Status                *model.TaskStatus                  `json:"status" validate:"omitempty,oneof=todo in_progress blocked done cancelled"`
+	DueAt                 model.Nullable[time.Time]          `json:"due_at"`
+	StartAt               model.Nullable[time.Time]          `json:"start_at"`
+	ProjectID             model.Nullable[primitive.ObjectID] `json:"project_id"`
+	Priority              *model.TaskPriority                `json:"priority"`
+	ChecklistAutoComplete *bool                              `json:"checklist_auto_complete"`
+	Tags                  *[]string                          `json:"tags" validate:"omitempty,max=20,dive,min=1,max=30"`
+	Recurrence            model.Nullable[model.Recurrence]   `json:"recurrence"`
+	ReminderOffsets       *[]int                             `json:"reminder_offsets" validate:"omitempty,max=10,dive,min=1,max=40320"`
 }
 
 // UpdateTask - Updates the task with the specified ID
@@ -163,50 +311,210 @@ func UpdateTask() gin.HandlerFunc {
 			return
 		}
 
-		var updatedFields struct {
-			Title   *string    `json:"title" validate:"omitempty,min=1"`
-			Status  *bool      `json:"status"`
-			Updated *time.Time `json:"updated"`
-		}
-
+		var updatedFields taskChanges
 		if err := c.ShouldBindJSON(&updatedFields); err != nil {
 			helper.RespondWithError(c, http.StatusBadRequest, "Invalid JSON input", err.Error())
 			return
 		}
 
-		if err := validate.Struct(updatedFields); err != nil {
-			helper.RespondWithError(c, http.StatusBadRequest, "Validation error", err.Error())
+		task, err := updateTask(c.Request.Context(), userID, username, id, updatedFields)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error updating task")
 			return
 		}
 
-		update := bson.M{}
-		if updatedFields.Title != nil {
-			update["title"] = *updatedFields.Title
+		helper.RespondWithSuccess(c, http.StatusOK, "Task updated successfully for "+username, task)
+	}
+}
+
+// updateTask - Applies the changes to one of the user's tasks, records them in its history and returns the updated task.
+// Status changes follow the workflow and are only applied if the status has not changed in the meantime.
+func updateTask(ctx context.Context, userID string, username string, id primitive.ObjectID, updatedFields taskChanges) (model.Task, error) {
+	if updatedFields.Tags != nil {
+		*updatedFields.Tags = normalizeTags(*updatedFields.Tags)
+	}
+
+	if err := validate.Struct(updatedFields); err != nil {
+		return model.Task{}, helper.NewRequestError(http.StatusBadRequest, "Validation error", err.Error())
+	}
+
+	current, err := findAccessibleTask(ctx, userID, id, model.RoleEditor)
+	if err != nil {
+		return current, err
+	}
+
+	// Shared tasks stay in their owner's projects, tags and board, whoever edits them
+	ownerID := current.UserID
+	collection := database.GetTaskCollection()
+	filter := helper.NotDeleted(helper.InWorkspace(ctx, bson.M{"_id": id, "user_id": ownerID}))
+
+	now := time.Now().UTC()
+	update := bson.M{}
+	unset := bson.M{}
+	if updatedFields.Title != nil {
+		update["title"] = *updatedFields.Title
+	}
+	if updatedFields.Priority != nil {
+		update["priority"] = *updatedFields.Priority
+	}
+
+	if updatedFields.Status != nil && *updatedFields.Status != current.Status {
+		if err := applyStatusChange(current, *updatedFields.Status, now, update, unset); err != nil {
+			return current, err
+		}
+		if err := checkBlockedStatus(ctx, current, *updatedFields.Status, update, unset); err != nil {
+			return current, err
+		}
+		// Only apply the transition if nobody changed the status in the meantime
+		filter["status"] = current.Status
+	}
+	if updatedFields.ChecklistAutoComplete != nil {
+		update["checklist_auto_complete"] = *updatedFields.ChecklistAutoComplete
+	}
+	if updatedFields.ReminderOffsets != nil {
+		update["reminder_offsets"] = *updatedFields.ReminderOffsets
+	}
+
+	dueAt, startAt := current.DueAt, current.StartAt
+	if updatedFields.DueAt.Set {
+		dueAt = updatedFields.DueAt.Value
+		setOrUnsetTime(update, unset, "due_at", dueAt)
+	}
+	if updatedFields.StartAt.Set {
+		startAt = updatedFields.StartAt.Value
+		setOrUnsetTime(update, unset, "start_at", startAt)
+	}
+	if err := validateTaskDates(startAt, dueAt); err != nil {
+		return current, helper.NewRequestError(http.StatusBadRequest, "Validation error", err.Error())
+	}
+
+	if updatedFields.Recurrence.Set {
+		if recurrence := updatedFields.Recurrence.Value; recurrence == nil {
+			unset["recurrence"] = ""
+		} else {
+			if err := prepareRecurrence(recurrence, current.ID, dueAt, current.Recurrence); err != nil {
+				return current, err
+			}
+			update["recurrence"] = *recurrence
 		}
-		if updatedFields.Status != nil {
-			update["status"] = *updatedFields.Status
+	} else if current.Recurrence != nil && dueAt == nil {
+		return current, helper.NewRequestError(http.StatusBadRequest, "Validation error", "Recurring tasks need a due_at, remove the recurrence first")
+	}
+
+	if updatedFields.ProjectID.Set {
+		if updatedFields.ProjectID.Value == nil {
+			unset["project_id"] = ""
+		} else {
+			if err := checkTaskProject(ctx, ownerID, *updatedFields.ProjectID.Value); err != nil {
+				return current, err
+			}
+			update["project_id"] = *updatedFields.ProjectID.Value
 		}
-		update["updated"] = time.Now().UTC()
 
-		collection := database.GetTaskCollection()
-		filter := bson.M{"_id": id, "user_id": userID}
+		if !sameObjectID(current.ProjectID, updatedFields.ProjectID.Value) {
+			rank, err := rankAtEnd(ctx, ownerID, updatedFields.ProjectID.Value, current.ParentID)
+			if err != nil {
+				return current, err
+			}
+			update["rank"] = rank
+		}
+	}
 
-		result, err := collection.UpdateOne(c.Request.Context(), filter, bson.M{"$set": update})
-		if err != nil {
-			helper.RespondWithError(c, http.StatusInternalServerError, "Error updating task", err.Error())
-			return
+	// Moving into another board column, by status or by project, must respect its WIP limit
+	projectID, status := current.ProjectID, current.Status
+	if updatedFields.ProjectID.Set {
+		projectID = updatedFields.ProjectID.Value
+	}
+	if updatedFields.Status != nil {
+		status = *updatedFields.Status
+	}
+	if status != current.Status || !sameObjectID(projectID, current.ProjectID) {
+		if err := checkWIPLimit(ctx, ownerID, current, projectID, status); err != nil {
+			return current, err
+		}
+	}
+
+	if updatedFields.Tags != nil {
+		if err := registerTags(ctx, ownerID, *updatedFields.Tags); err != nil {
+			return current, fmt.Errorf("registering tags: %w", err)
 		}
+		update["tags"] = *updatedFields.Tags
+	}
+	update["updated_at"] = now
 
-		if result.MatchedCount == 0 {
-			helper.RespondWithError(c, http.StatusNotFound, "Task not found", "No task found for the specified ID and user "+username)
-			return
+	changes := bson.M{"$set": update}
+	if len(unset) > 0 {
+		changes["$unset"] = unset
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var task model.Task
+	if err := collection.FindOneAndUpdate(ctx, filter, changes, opts).Decode(&task); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return current, helper.NewRequestError(http.StatusConflict, "Task was modified concurrently", "The task status changed while updating, please retry")
+		}
+		return current, fmt.Errorf("updating task: %w", err)
+	}
+	logRevision(ctx, userID, username, model.RevisionUpdate, &current, task)
+	logOperation(ctx, model.Operation{UserID: userID, OwnerID: ownerID, Action: model.OperationUpdate, TaskID: &id, Before: &current, After: &task, Stamp: task.Updated})
+
+	if err := afterStatusChange(ctx, ownerID, task, current.Status, now); err != nil {
+		return task, fmt.Errorf("applying status change: %w", err)
+	}
+
+	if updatedFields.DueAt.Set || updatedFields.ReminderOffsets != nil || task.Status != current.Status {
+		if err := helper.ScheduleReminders(ctx, task); err != nil {
+			log.Printf("Error scheduling reminders for task %s: %v", task.ID.Hex(), err)
+		}
+	}
+	return task, nil
+}
+
+// applyStatusChange - Checks a status transition against the workflow and adds it to the update,
+// setting completed_at when the task is done and clearing it when it is reopened
+func applyStatusChange(current model.Task, next model.TaskStatus, now time.Time, update, unset bson.M) error {
+	if !current.Status.CanTransitionTo(next) {
+		return helper.NewRequestError(http.StatusConflict, "Invalid status transition", "Cannot move a task from "+string(current.Status)+" to "+string(next))
+	}
+
+	update["status"] = next
+	if next == model.StatusDone {
+		update["completed_at"] = now
+	} else if current.Status == model.StatusDone {
+		unset["completed_at"] = ""
+	}
+	return nil
+}
+
+// afterStatusChange - Applies the side effects of a task moving from previous to its current status:
+// closing a parent closes its subtasks, closing or reopening a task unblocks or blocks the tasks waiting
+// for it, and completing a recurring task schedules the next occurrence
+func afterStatusChange(ctx context.Context, userID string, task model.Task, previous model.TaskStatus, now time.Time) error {
+	if task.Status == previous {
+		return nil
+	}
+	if err := cascadeStatusToDescendants(ctx, userID, task, now); err != nil {
+		return err
+	}
+	if task.Status.IsClosed() != previous.IsClosed() {
+		if err := syncDependents(ctx, []primitive.ObjectID{task.ID}); err != nil {
+			return err
 		}
+	}
+	return createNextOccurrence(ctx, task)
+}
 
-		helper.RespondWithSuccess(c, http.StatusOK, "Task updated successfully for "+username, update)
+// setOrUnsetTime - Adds a timestamp to the $set document, or to $unset when it is being cleared
+func setOrUnsetTime(update, unset bson.M, field string, value *time.Time) {
+	if value == nil {
+		unset[field] = ""
+		return
 	}
+	update[field] = value.UTC()
 }
 
-// DeleteTask - Deletes the task with the specified ID
+// DeleteTask - Moves the task with the specified ID to the trash along with its subtasks.
+// With ?children=promote the subtasks are kept and moved up to the deleted task's parent instead.
 func DeleteTask() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, _, valid := helper.GetUserDetails(c)
@@ -222,24 +530,104 @@ func DeleteTask() gin.HandlerFunc {
 			return
 		}
 
-		collection := database.GetTaskCollection()
-		filter := bson.M{"_id": id, "user_id": userID}
-		result, err := collection.DeleteOne(c.Request.Context(), filter)
-		if err != nil {
-			helper.RespondWithError(c, http.StatusInternalServerError, "Error deleting task", err.Error())
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		if _, err := deleteTask(ctx, userID, id, c.DefaultQuery("children", "delete")); err != nil {
+			helper.RespondWithRequestError(c, err, "Error deleting task")
 			return
 		}
 
-		if result.DeletedCount == 0 {
-			helper.RespondWithError(c, http.StatusNotFound, "Task not found", "No task found for the specified ID and user")
-			return
+		helper.RespondWithSuccess(c, http.StatusOK, "Task moved to trash", nil)
+	}
+}
+
+// taskDeletion - What deleteTask changed: the tasks it trashed, all marked with DeletedAt,
+// and the subtasks it promoted to the deleted task's parent
+type taskDeletion struct {
+	Trashed   []primitive.ObjectID
+	Promoted  []primitive.ObjectID
+	DeletedAt time.Time
+}
+
+// deleteTask - Moves a task the user owns, or that is shared with them as owner, to the trash
+// and adds the deletion to the user's undo log
+func deleteTask(ctx context.Context, userID string, id primitive.ObjectID, children string) (taskDeletion, error) {
+	task, err := findAccessibleTask(ctx, userID, id, model.RoleOwner)
+	if err != nil {
+		return taskDeletion{}, err
+	}
+	deletion, err := moveToTrash(ctx, task.UserID, id, children)
+	if err != nil {
+		return deletion, err
+	}
+	logOperation(ctx, model.Operation{
+		UserID:   userID,
+		OwnerID:  task.UserID,
+		Action:   model.OperationDelete,
+		TaskID:   &id,
+		Children: children,
+		Promoted: deletion.Promoted,
+		Stamp:    deletion.DeletedAt,
+	})
+	return deletion, nil
+}
+
+// moveToTrash - Moves one of the user's tasks to the trash. children decides whether its subtasks go to the trash
+// with it, marked with the same deleted_at so they are restored together, or are promoted to its parent.
+func moveToTrash(ctx context.Context, userID string, id primitive.ObjectID, children string) (taskDeletion, error) {
+	// MongoDB keeps milliseconds only, so the stamp is truncated to compare equal to the stored one
+	deletion := taskDeletion{Trashed: []primitive.ObjectID{id}, DeletedAt: time.Now().UTC().Truncate(time.Millisecond)}
+	if children != "delete" && children != "promote" {
+		return deletion, helper.NewRequestError(http.StatusBadRequest, "Invalid query parameters", "children must be delete or promote")
+	}
+
+	task, err := findTask(ctx, userID, id)
+	if err != nil {
+		return deletion, err
+	}
+
+	collection := database.GetTaskCollection()
+	if children == "promote" {
+		moveUp := bson.M{"$unset": bson.M{"parent_id": ""}}
+		if task.ParentID != nil {
+			moveUp = bson.M{"$set": bson.M{"parent_id": *task.ParentID}}
+		}
+		if deletion.Promoted, err = childTaskIDs(ctx, userID, id); err == nil {
+			_, err = collection.UpdateMany(ctx, helper.InWorkspace(ctx, bson.M{"_id": bson.M{"$in": deletion.Promoted}}), moveUp)
+		}
+	} else {
+		var descendants []model.Task
+		if descendants, err = findDescendants(ctx, userID, id); err == nil {
+			deletion.Trashed = append(deletion.Trashed, taskIDs(descendants)...)
 		}
+	}
+	if err != nil {
+		return deletion, fmt.Errorf("updating subtasks: %w", err)
+	}
 
-		helper.RespondWithSuccess(c, http.StatusOK, "Task deleted successfully", nil)
+	result, err := collection.UpdateMany(ctx,
+		helper.NotDeleted(helper.InWorkspace(ctx, bson.M{"_id": bson.M{"$in": deletion.Trashed}, "user_id": userID})),
+		bson.M{"$set": bson.M{"deleted_at": deletion.DeletedAt}},
+	)
+	if err != nil {
+		return deletion, fmt.Errorf("deleting task: %w", err)
 	}
+	if result.ModifiedCount == 0 {
+		return deletion, helper.NewRequestError(http.StatusNotFound, "Task not found", "No task found for the specified ID and user")
+	}
+
+	if err := helper.CancelReminders(ctx, deletion.Trashed); err != nil {
+		log.Printf("Error cancelling reminders for task %s: %v", id.Hex(), err)
+	}
+	// Trashed tasks no longer hold up the tasks waiting for them
+	if err := syncDependents(ctx, deletion.Trashed); err != nil {
+		log.Printf("Error unblocking dependents of task %s: %v", id.Hex(), err)
+	}
+	return deletion, nil
 }
 
-// DeleteAllTasks - Deletes all tasks
+// DeleteAllTasks - Moves all of the user's tasks in the workspace to the trash
 func DeleteAllTasks() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, _, valid := helper.GetUserDetails(c)
@@ -248,19 +636,39 @@ func DeleteAllTasks() gin.HandlerFunc {
 			return
 		}
 
+		ctx := c.Request.Context()
 		collection := database.GetTaskCollection()
-		filter := bson.M{"user_id": userID}
-		result, err := collection.DeleteMany(c.Request.Context(), filter)
+		filter := helper.NotDeleted(helper.InWorkspace(ctx, bson.M{"user_id": userID}))
+		deletedAt := time.Now().UTC().Truncate(time.Millisecond)
+		result, err := collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"deleted_at": deletedAt}})
 		if err != nil {
 			helper.RespondWithError(c, http.StatusInternalServerError, "Error deleting all tasks", err.Error())
 			return
 		}
 
-		if result.DeletedCount == 0 {
+		if result.ModifiedCount == 0 {
 			helper.RespondWithError(c, http.StatusNotFound, "No tasks found", "No tasks found for the user to delete")
 			return
 		}
 
-		helper.RespondWithSuccess(c, http.StatusOK, "All tasks deleted successfully", nil)
+		// Only the reminders of this workspace's tasks are cancelled, the user's other workspaces keep theirs
+		var trashed []model.Task
+		cursor, err := collection.Find(ctx, helper.InWorkspace(ctx, bson.M{"user_id": userID, "deleted_at": deletedAt}), options.Find().SetProjection(bson.M{"_id": 1}))
+		if err == nil {
+			err = cursor.All(ctx, &trashed)
+		}
+		if err == nil {
+			err = helper.CancelReminders(ctx, taskIDs(trashed))
+		}
+		if err != nil {
+			log.Printf("Error cancelling reminders for user %s: %v", userID, err)
+		}
+		// Other members' tasks may have been waiting for the trashed ones
+		if err := syncDependents(ctx, taskIDs(trashed)); err != nil {
+			log.Printf("Error unblocking dependents for user %s: %v", userID, err)
+		}
+		logOperation(ctx, model.Operation{UserID: userID, Action: model.OperationDeleteAll, Stamp: deletedAt})
+
+		helper.RespondWithSuccess(c, http.StatusOK, "All tasks moved to trash", nil)
 	}
 }
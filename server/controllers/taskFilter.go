@@ -0,0 +1,299 @@
+package controller
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	model "task-manager/server/models"
+)
+
+const (
+	maxFilterLength = 500
+	maxFilterTerms  = 20
+)
+
+// FilterError - A filter expression that could not be parsed, pointing at the offending token
+type FilterError struct {
+	Position int
+	Token    string
+	Reason   string
+}
+
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("filter error at position %d (%s): %s", e.Position, e.Token, e.Reason)
+}
+
+// filterTerm - One whitespace-separated term of a filter expression, e.g. -tag:someday or due<2026-11-01
+type filterTerm struct {
+	Position int
+	Token    string
+	Negated  bool
+	Field    string
+	Operator string
+	Value    string
+}
+
+func (t filterTerm) errorf(format string, args ...interface{}) *FilterError {
+	return &FilterError{Position: t.Position, Token: t.Token, Reason: fmt.Sprintf(format, args...)}
+}
+
+// filterTermPattern - An optional "-", a field name, an operator and a value that may be "quoted"
+var filterTermPattern = regexp.MustCompile(`^(-?)([a-z_]+)(:|<=|>=|<|>)(.*)$`)
+
+// filterOperators - The Mongo comparison each filter operator stands for
+var filterOperators = map[string]string{":": "$eq", "<": "$lt", "<=": "$lte", ">": "$gt", ">=": "$gte"}
+
+// relativeDatePattern - Offsets from today such as +3d, -1d or +2w
+var relativeDatePattern = regexp.MustCompile(`^([+-]\d{1,4})([dw])$`)
+
+// filterDateFields - Filter field names and the task fields they compare against
+var filterDateFields = map[string]string{
+	"due":       "due_at",
+	"start":     "start_at",
+	"created":   "created_at",
+	"updated":   "updated_at",
+	"completed": "completed_at",
+}
+
+// filterMatchFields - The remaining filter fields, which only support ':'
+var filterMatchFields = map[string]bool{"status": true, "tag": true, "project": true, "title": true, "is": true, "has": true, "no": true}
+
+// filterPresenceFields - The fields has: and no: can test for
+var filterPresenceFields = map[string]string{
+	"due":        "due_at",
+	"start":      "start_at",
+	"project":    "project_id",
+	"parent":     "parent_id",
+	"recurrence": "recurrence",
+}
+
+// tokenizeFilter - Splits a filter expression on whitespace, keeping quoted values together
+// and remembering where each token starts (1-based) for error messages
+func tokenizeFilter(raw string) ([]filterTerm, error) {
+	var terms []filterTerm
+	start := -1
+	quoted := false
+	for i, r := range raw + " " {
+		switch {
+		case r == '"':
+			if start < 0 {
+				start = i
+			}
+			quoted = !quoted
+		case r == ' ' || r == '\t' || r == '\n':
+			if start >= 0 && !quoted {
+				terms = append(terms, filterTerm{Position: start + 1, Token: raw[start:i]})
+				start = -1
+			}
+		default:
+			if start < 0 {
+				start = i
+			}
+		}
+	}
+	if quoted {
+		return nil, &FilterError{Position: start + 1, Token: raw[start:], Reason: "unterminated quote"}
+	}
+	return terms, nil
+}
+
+// parseTaskFilter - Parses a filter expression such as `status:open tag:work due<2026-11-01 -tag:someday`
+// into a Mongo filter. Terms are ANDed together and a leading "-" negates a term.
+// Only the fields below are understood, and values are always converted to typed values before
+// they reach the filter, so user input can never inject operators of its own.
+func parseTaskFilter(raw string, now time.Time) (bson.M, error) {
+	if len(raw) > maxFilterLength {
+		return nil, fmt.Errorf("filter must be at most %d characters", maxFilterLength)
+	}
+
+	terms, err := tokenizeFilter(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(terms) > maxFilterTerms {
+		return nil, fmt.Errorf("filter accepts at most %d terms", maxFilterTerms)
+	}
+
+	conditions := bson.A{}
+	for _, term := range terms {
+		match := filterTermPattern.FindStringSubmatch(term.Token)
+		if match == nil {
+			return nil, term.errorf("expected field:value, field<value or field>value")
+		}
+		term.Negated = match[1] == "-"
+		term.Field, term.Operator, term.Value = match[2], match[3], strings.Trim(match[4], `"`)
+		if term.Value == "" {
+			return nil, term.errorf("missing value")
+		}
+
+		condition, err := filterCondition(term, now)
+		if err != nil {
+			return nil, err
+		}
+		if term.Negated {
+			condition = bson.M{"$nor": bson.A{condition}}
+		}
+		conditions = append(conditions, condition)
+	}
+
+	if len(conditions) == 0 {
+		return bson.M{}, nil
+	}
+	return bson.M{"$and": conditions}, nil
+}
+
+// filterCondition - Converts a single term into its Mongo condition
+func filterCondition(term filterTerm, now time.Time) (bson.M, error) {
+	if dateField, ok := filterDateFields[term.Field]; ok {
+		return dateCondition(term, dateField, now)
+	}
+	if term.Field == "priority" {
+		return priorityCondition(term)
+	}
+	if !filterMatchFields[term.Field] {
+		return nil, term.errorf("unknown field %q", term.Field)
+	}
+	if term.Operator != ":" {
+		return nil, term.errorf("%s can only be matched with ':'", term.Field)
+	}
+
+	values := strings.Split(term.Value, ",")
+	switch term.Field {
+	case "status":
+		switch term.Value {
+		case "open":
+			return bson.M{"status": bson.M{"$nin": model.ClosedStatuses}}, nil
+		case "closed":
+			return bson.M{"status": bson.M{"$in": model.ClosedStatuses}}, nil
+		}
+
+		var statuses []model.TaskStatus
+		for _, value := range values {
+			status := model.TaskStatus(value)
+			if _, ok := model.TaskTransitions[status]; !ok {
+				return nil, term.errorf("unknown status %q", value)
+			}
+			statuses = append(statuses, status)
+		}
+		return bson.M{"status": bson.M{"$in": statuses}}, nil
+
+	case "tag":
+		tags := normalizeTags(values)
+		if len(tags) == 0 {
+			return nil, term.errorf("missing tag name")
+		}
+		return bson.M{"tags": bson.M{"$in": tags}}, nil
+
+	case "project":
+		if term.Value == "inbox" {
+			return bson.M{"project_id": nil}, nil
+		}
+		var ids []primitive.ObjectID
+		for _, value := range values {
+			id, err := primitive.ObjectIDFromHex(value)
+			if err != nil {
+				return nil, term.errorf("project must be a project ID or inbox")
+			}
+			ids = append(ids, id)
+		}
+		return bson.M{"project_id": bson.M{"$in": ids}}, nil
+
+	case "title":
+		return bson.M{"title": bson.M{"$regex": regexp.QuoteMeta(term.Value), "$options": "i"}}, nil
+
+	case "is":
+		switch term.Value {
+		case "open":
+			return bson.M{"status": bson.M{"$nin": model.ClosedStatuses}}, nil
+		case "closed":
+			return bson.M{"status": bson.M{"$in": model.ClosedStatuses}}, nil
+		case "overdue":
+			return bson.M{"due_at": bson.M{"$lt": now}, "status": bson.M{"$nin": model.ClosedStatuses}}, nil
+		case "subtask":
+			return bson.M{"parent_id": bson.M{"$ne": nil}}, nil
+		}
+		return nil, term.errorf("is: accepts open, closed, overdue or subtask")
+
+	case "has", "no":
+		field, ok := filterPresenceFields[term.Value]
+		if !ok {
+			return nil, term.errorf("%s: accepts due, start, project, parent or recurrence", term.Field)
+		}
+		if term.Field == "no" {
+			return bson.M{field: nil}, nil
+		}
+		return bson.M{field: bson.M{"$ne": nil}}, nil
+	}
+
+	return nil, term.errorf("unknown field %q", term.Field)
+}
+
+// priorityCondition - Matches priorities by name, e.g. priority:high or priority>=medium
+func priorityCondition(term filterTerm) (bson.M, error) {
+	priority, err := model.ParseTaskPriority(term.Value)
+	if err != nil {
+		return nil, term.errorf("%s", err.Error())
+	}
+	return bson.M{"priority": bson.M{filterOperators[term.Operator]: int(priority)}}, nil
+}
+
+// dateCondition - Compares a date field against a timestamp, a day or a day relative to today.
+// A day covers its whole 24 hours in UTC, so due:2026-11-01 matches any time that day
+// and due<=2026-11-01 includes the end of it.
+func dateCondition(term filterTerm, field string, now time.Time) (bson.M, error) {
+	if t, err := time.Parse(time.RFC3339, term.Value); err == nil {
+		t = t.UTC()
+		return bson.M{field: bson.M{filterOperators[term.Operator]: t}}, nil
+	}
+
+	day, err := parseFilterDay(term.Value, now)
+	if err != nil {
+		return nil, term.errorf("%s", err.Error())
+	}
+	next := day.AddDate(0, 0, 1)
+
+	switch term.Operator {
+	case "<":
+		return bson.M{field: bson.M{"$lt": day}}, nil
+	case "<=":
+		return bson.M{field: bson.M{"$lt": next}}, nil
+	case ">":
+		return bson.M{field: bson.M{"$gte": next}}, nil
+	case ">=":
+		return bson.M{field: bson.M{"$gte": day}}, nil
+	}
+	return bson.M{field: bson.M{"$gte": day, "$lt": next}}, nil
+}
+
+// parseFilterDay - Resolves YYYY-MM-DD, today, tomorrow, yesterday or an offset like +3d or -2w
+// to the start of that day in UTC
+func parseFilterDay(value string, now time.Time) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch value {
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+
+	if match := relativeDatePattern.FindStringSubmatch(value); match != nil {
+		offset, _ := strconv.Atoi(match[1])
+		if match[2] == "w" {
+			offset *= 7
+		}
+		return today.AddDate(0, 0, offset), nil
+	}
+
+	if day, err := time.Parse(time.DateOnly, value); err == nil {
+		return day, nil
+	}
+	return time.Time{}, fmt.Errorf("expected a YYYY-MM-DD date, an RFC 3339 timestamp, today, tomorrow, yesterday or an offset like +3d")
+}
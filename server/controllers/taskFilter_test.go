@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	model "task-manager/server/models"
+)
+
+func TestParseTaskFilter(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 30, 0, 0, time.UTC)
+	today := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	open := bson.M{"status": bson.M{"$nin": model.ClosedStatuses}}
+	projectID := primitive.NewObjectID()
+
+	tests := []struct {
+		name string
+		raw  string
+		want bson.M
+	}{
+		{name: "empty", raw: "", want: bson.M{}},
+		{name: "only whitespace", raw: " \t\n ", want: bson.M{}},
+		{name: "single term", raw: "status:open", want: bson.M{"$and": bson.A{open}}},
+		{name: "terms are ANDed in order", raw: "status:open tag:work", want: bson.M{"$and": bson.A{
+			open,
+			bson.M{"tags": bson.M{"$in": []string{"work"}}},
+		}}},
+		{name: "negation applies to its own term only", raw: "-tag:someday status:open", want: bson.M{"$and": bson.A{
+			bson.M{"$nor": bson.A{bson.M{"tags": bson.M{"$in": []string{"someday"}}}}},
+			open,
+		}}},
+		{name: "negated date comparison", raw: "-due<today", want: bson.M{"$and": bson.A{
+			bson.M{"$nor": bson.A{bson.M{"due_at": bson.M{"$lt": today}}}},
+		}}},
+		{name: "comma separated values are ORed", raw: "status:todo,blocked", want: bson.M{"$and": bson.A{
+			bson.M{"status": bson.M{"$in": []model.TaskStatus{model.StatusTodo, model.StatusBlocked}}},
+		}}},
+		{name: "tags are normalized", raw: "tag:Work,,work", want: bson.M{"$and": bson.A{
+			bson.M{"tags": bson.M{"$in": []string{"work"}}},
+		}}},
+		{name: "quoted value keeps its spaces", raw: `title:"weekly report"`, want: bson.M{"$and": bson.A{
+			bson.M{"title": bson.M{"$regex": "weekly report", "$options": "i"}},
+		}}},
+		{name: "quoted value keeps operator characters literal", raw: `title:"a<b:c (d)"`, want: bson.M{"$and": bson.A{
+			bson.M{"title": bson.M{"$regex": `a<b:c \(d\)`, "$options": "i"}},
+		}}},
+		{name: "quoted value next to other terms", raw: `title:"two words" -is:closed`, want: bson.M{"$and": bson.A{
+			bson.M{"title": bson.M{"$regex": "two words", "$options": "i"}},
+			bson.M{"$nor": bson.A{bson.M{"status": bson.M{"$in": model.ClosedStatuses}}}},
+		}}},
+		{name: "day covers all of it", raw: "due:2026-11-01", want: bson.M{"$and": bson.A{
+			bson.M{"due_at": bson.M{"$gte": time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), "$lt": time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)}},
+		}}},
+		{name: "on or before a day includes its end", raw: "due<=tomorrow", want: bson.M{"$and": bson.A{
+			bson.M{"due_at": bson.M{"$lt": today.AddDate(0, 0, 2)}},
+		}}},
+		{name: "after a day starts the next one", raw: "created>yesterday", want: bson.M{"$and": bson.A{
+			bson.M{"created_at": bson.M{"$gte": today}},
+		}}},
+		{name: "relative weeks", raw: "start>=-2w", want: bson.M{"$and": bson.A{
+			bson.M{"start_at": bson.M{"$gte": today.AddDate(0, 0, -14)}},
+		}}},
+		{name: "timestamp is compared as is", raw: "updated>2026-10-01T12:00:00+02:00", want: bson.M{"$and": bson.A{
+			bson.M{"updated_at": bson.M{"$gt": time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)}},
+		}}},
+		{name: "priority comparison", raw: "priority>=high", want: bson.M{"$and": bson.A{
+			bson.M{"priority": bson.M{"$gte": int(model.PriorityHigh)}},
+		}}},
+		{name: "inbox project", raw: "project:inbox", want: bson.M{"$and": bson.A{
+			bson.M{"project_id": nil},
+		}}},
+		{name: "project by ID", raw: "project:" + projectID.Hex(), want: bson.M{"$and": bson.A{
+			bson.M{"project_id": bson.M{"$in": []primitive.ObjectID{projectID}}},
+		}}},
+		{name: "overdue", raw: "is:overdue", want: bson.M{"$and": bson.A{
+			bson.M{"due_at": bson.M{"$lt": now}, "status": bson.M{"$nin": model.ClosedStatuses}},
+		}}},
+		{name: "presence", raw: "has:due no:project", want: bson.M{"$and": bson.A{
+			bson.M{"due_at": bson.M{"$ne": nil}},
+			bson.M{"project_id": nil},
+		}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseTaskFilter(test.raw, now)
+			if err != nil {
+				t.Fatalf("parseTaskFilter(%q) returned error: %v", test.raw, err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseTaskFilter(%q) =\n%#v\nwant\n%#v", test.raw, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseTaskFilterErrors(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		raw      string
+		position int
+		token    string
+		reason   string
+	}{
+		{name: "unterminated quote", raw: `tag:work title:"open`, position: 10, token: `title:"open`, reason: "unterminated quote"},
+		{name: "not a term", raw: "status:open urgent", position: 13, token: "urgent", reason: "expected field:value, field<value or field>value"},
+		{name: "missing value", raw: "tag:", position: 1, token: "tag:", reason: "missing value"},
+		{name: "empty quoted value", raw: `title:""`, position: 1, token: `title:""`, reason: "missing value"},
+		{name: "unknown field", raw: "owner:me", position: 1, token: "owner:me", reason: `unknown field "owner"`},
+		{name: "comparison on a match field", raw: "tag>work", position: 1, token: "tag>work", reason: "tag can only be matched with ':'"},
+		{name: "unknown status", raw: "status:todo,later", position: 1, token: "status:todo,later", reason: `unknown status "later"`},
+		{name: "blank tag", raw: "tag:,", position: 1, token: "tag:,", reason: "missing tag name"},
+		{name: "bad project", raw: "project:work", position: 1, token: "project:work", reason: "project must be a project ID or inbox"},
+		{name: "bad is", raw: "is:late", position: 1, token: "is:late", reason: "is: accepts open, closed, overdue or subtask"},
+		{name: "bad has", raw: "has:tags", position: 1, token: "has:tags", reason: "has: accepts due, start, project, parent or recurrence"},
+		{name: "bad priority", raw: "priority:huge", position: 1, token: "priority:huge", reason: `unknown priority "huge", expected one of none, low, medium, high, urgent`},
+		{name: "bad date", raw: "  due<soon", position: 3, token: "due<soon", reason: "expected a YYYY-MM-DD date, an RFC 3339 timestamp, today, tomorrow, yesterday or an offset like +3d"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseTaskFilter(test.raw, now)
+			var filterErr *FilterError
+			if !errors.As(err, &filterErr) {
+				t.Fatalf("parseTaskFilter(%q) returned %v, want a FilterError", test.raw, err)
+			}
+			if filterErr.Position != test.position || filterErr.Token != test.token || filterErr.Reason != test.reason {
+				t.Errorf("parseTaskFilter(%q) = %+v, want position %d, token %q, reason %q", test.raw, *filterErr, test.position, test.token, test.reason)
+			}
+		})
+	}
+}
+
+func TestParseTaskFilterLimits(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{name: "too long", raw: "title:" + strings.Repeat("a", maxFilterLength), want: "filter must be at most 500 characters"},
+		{name: "too many terms", raw: strings.Repeat("is:open ", maxFilterTerms+1), want: "filter accepts at most 20 terms"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseTaskFilter(test.raw, now)
+			if err == nil || err.Error() != test.want {
+				t.Errorf("parseTaskFilter returned %v, want %q", err, test.want)
+			}
+		})
+	}
+}
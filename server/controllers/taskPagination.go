@@ -0,0 +1,247 @@
+package controller
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	helper "task-manager/server/helpers"
+	model "task-manager/server/models"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// pageCursor - The position a page starts from: the sort key values of the task on the
+// edge of the previous page, and whether to read forwards or backwards from it.
+// It is handed to clients base64-encoded, so they treat it as an opaque token.
+type pageCursor struct {
+	Backward bool   `bson:"b"`
+	Sort     string `bson:"s"`
+	Values   bson.A `bson:"v"`
+}
+
+func (pc pageCursor) encode() (string, error) {
+	raw, err := bson.Marshal(pc)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// decodePageCursor - Parses a cursor token, rejecting tokens issued for a different sort order.
+// The values end up in the query, so anything but a plain value of the sort key's type is rejected too.
+func decodePageCursor(token string, sort bson.D) (*pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("cursor is malformed")
+	}
+
+	var pc pageCursor
+	if err := bson.Unmarshal(raw, &pc); err != nil || len(pc.Values) != len(sort) {
+		return nil, fmt.Errorf("cursor is malformed")
+	}
+	if pc.Sort != sortSpec(sort) {
+		return nil, fmt.Errorf("cursor was issued for a different sort order")
+	}
+	for i, key := range sort {
+		if !isSortValue(key.Key, pc.Values[i]) {
+			return nil, fmt.Errorf("cursor is malformed")
+		}
+	}
+	return &pc, nil
+}
+
+// isSortValue - Reports whether value can be stored under the sort key: null, or a scalar of the key's type
+func isSortValue(key string, value interface{}) bool {
+	switch value.(type) {
+	case nil:
+		return true
+	case string:
+		return key == "rank" || key == "status" || key == "title"
+	case int32, int64, float64:
+		return key == "priority"
+	case primitive.ObjectID:
+		return key == "_id"
+	case primitive.DateTime:
+		return strings.HasSuffix(key, "_at")
+	}
+	return false
+}
+
+// parsePageSize - Reads ?limit=, defaulting to defaultPageSize
+func parsePageSize(c *gin.Context) (int, error) {
+	raw := c.Query("limit")
+	if raw == "" {
+		return defaultPageSize, nil
+	}
+
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 || limit > maxPageSize {
+		return 0, fmt.Errorf("limit must be between 1 and %d", maxPageSize)
+	}
+	return limit, nil
+}
+
+// keysetFilter - Matches the documents that come after values in the given sort order.
+// Missing and null values sort lowest in Mongo, and $gt/$lt never match them, so they are handled explicitly.
+func keysetFilter(sort bson.D, values bson.A) bson.M {
+	var branches []bson.M
+	for i, key := range sort {
+		branch := bson.M{}
+		for j := 0; j < i; j++ {
+			branch[sort[j].Key] = values[j]
+		}
+
+		value := values[i]
+		ascending := key.Value.(int) > 0
+		switch {
+		case value == nil && ascending:
+			branch[key.Key] = bson.M{"$ne": nil}
+		case value == nil:
+			// Nothing sorts below null, so this branch matches nothing
+			continue
+		case ascending:
+			branch[key.Key] = bson.M{"$gt": value}
+		default:
+			branch = bson.M{"$and": bson.A{branch, bson.M{"$or": bson.A{
+				bson.M{key.Key: bson.M{"$lt": value}},
+				bson.M{key.Key: nil},
+			}}}}
+		}
+		branches = append(branches, branch)
+	}
+
+	if len(branches) == 0 {
+		return bson.M{"_id": bson.M{"$exists": false}}
+	}
+	return bson.M{"$or": branches}
+}
+
+// reverseSort - Flips every key of a sort so a page can be read backwards
+func reverseSort(sort bson.D) bson.D {
+	reversed := make(bson.D, len(sort))
+	for i, key := range sort {
+		reversed[i] = bson.E{Key: key.Key, Value: -key.Value.(int)}
+	}
+	return reversed
+}
+
+// sortValues - Pulls the sort key values out of a raw task document, using nil for missing fields
+func sortValues(doc bson.Raw, sort bson.D) bson.A {
+	values := make(bson.A, len(sort))
+	for i, key := range sort {
+		value, err := doc.LookupErr(key.Key)
+		if err != nil || value.Type == bson.TypeNull {
+			continue
+		}
+		var decoded interface{}
+		if err := value.Unmarshal(&decoded); err == nil {
+			values[i] = decoded
+		}
+	}
+	return values
+}
+
+// sortSpec - A canonical form of the sort, used to tie cursors to the order they were issued for
+func sortSpec(sort bson.D) string {
+	keys := make([]string, len(sort))
+	for i, key := range sort {
+		keys[i] = key.Key
+		if key.Value.(int) < 0 {
+			keys[i] = "-" + key.Key
+		}
+	}
+	return strings.Join(keys, ",")
+}
+
+// findTaskPage - Reads one page of tasks for the filter, decoding them one at a time off the Mongo cursor
+// so that no more than limit+1 tasks are ever held in memory.
+// One extra task is read past the page to learn whether there is another page after it.
+func findTaskPage(ctx context.Context, collection *mongo.Collection, filter bson.M, sort bson.D, limit int, cursorToken string) (model.TaskPage, error) {
+	page := model.TaskPage{Tasks: []model.Task{}}
+	spec := sortSpec(sort)
+
+	var position *pageCursor
+	if cursorToken != "" {
+		var err error
+		if position, err = decodePageCursor(cursorToken, sort); err != nil {
+			return page, helper.NewRequestError(http.StatusBadRequest, "Invalid query parameters", err.Error())
+		}
+	}
+
+	backward := position != nil && position.Backward
+	readSort := sort
+	if backward {
+		readSort = reverseSort(sort)
+	}
+
+	query := filter
+	if position != nil {
+		query = bson.M{"$and": bson.A{filter, keysetFilter(readSort, position.Values)}}
+	}
+
+	opts := options.Find().SetSort(readSort).SetLimit(int64(limit + 1)).SetBatchSize(int32(limit + 1))
+	cursor, err := collection.Find(ctx, query, opts)
+	if err != nil {
+		return page, err
+	}
+	defer cursor.Close(ctx)
+
+	var edges []bson.A
+	hasMore := false
+	for cursor.Next(ctx) {
+		if len(page.Tasks) == limit {
+			hasMore = true
+			break
+		}
+		var task model.Task
+		if err := cursor.Decode(&task); err != nil {
+			return page, err
+		}
+		page.Tasks = append(page.Tasks, task)
+		edges = append(edges, sortValues(cursor.Current, sort))
+	}
+	if err := cursor.Err(); err != nil {
+		return page, err
+	}
+
+	if backward {
+		for i, j := 0, len(page.Tasks)-1; i < j; i, j = i+1, j-1 {
+			page.Tasks[i], page.Tasks[j] = page.Tasks[j], page.Tasks[i]
+			edges[i], edges[j] = edges[j], edges[i]
+		}
+	}
+	if len(edges) == 0 {
+		return page, nil
+	}
+
+	// Reading forwards, there is a next page if the extra task turned up and a previous one if we came from a cursor.
+	// Reading backwards it is the other way round.
+	hasNext, hasPrev := hasMore, position != nil
+	if backward {
+		hasNext, hasPrev = true, hasMore
+	}
+	if hasNext {
+		if page.NextCursor, err = (pageCursor{Sort: spec, Values: edges[len(edges)-1]}).encode(); err != nil {
+			return page, err
+		}
+	}
+	if hasPrev {
+		if page.PrevCursor, err = (pageCursor{Backward: true, Sort: spec, Values: edges[0]}).encode(); err != nil {
+			return page, err
+		}
+	}
+	return page, nil
+}
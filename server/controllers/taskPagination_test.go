@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDecodePageCursor(t *testing.T) {
+	sort := bson.D{{Key: "priority", Value: -1}, {Key: "due_at", Value: 1}, {Key: "title", Value: 1}, {Key: "_id", Value: -1}}
+	due := primitive.NewDateTimeFromTime(time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC))
+	id := primitive.NewObjectID()
+
+	tests := []struct {
+		name   string
+		cursor pageCursor
+		want   string
+	}{
+		{name: "plain values", cursor: pageCursor{Sort: sortSpec(sort), Values: bson.A{int32(3), due, "report", id}}},
+		{name: "null values", cursor: pageCursor{Sort: sortSpec(sort), Values: bson.A{nil, nil, nil, id}}},
+		{name: "different sort", cursor: pageCursor{Sort: "priority,_id", Values: bson.A{int32(3), due, "report", id}}, want: "cursor was issued for a different sort order"},
+		{name: "too few values", cursor: pageCursor{Sort: sortSpec(sort), Values: bson.A{int32(3), due, id}}, want: "cursor is malformed"},
+		{name: "operator document", cursor: pageCursor{Sort: sortSpec(sort), Values: bson.A{int32(3), due, bson.M{"$regex": ".*"}, id}}, want: "cursor is malformed"},
+		{name: "array", cursor: pageCursor{Sort: sortSpec(sort), Values: bson.A{int32(3), due, bson.A{"a"}, id}}, want: "cursor is malformed"},
+		{name: "wrong type for the key", cursor: pageCursor{Sort: sortSpec(sort), Values: bson.A{"3", due, "report", id}}, want: "cursor is malformed"},
+		{name: "string for a date", cursor: pageCursor{Sort: sortSpec(sort), Values: bson.A{int32(3), "2026-10-18", "report", id}}, want: "cursor is malformed"},
+		{name: "string for the ID", cursor: pageCursor{Sort: sortSpec(sort), Values: bson.A{int32(3), due, "report", id.Hex()}}, want: "cursor is malformed"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			token, err := test.cursor.encode()
+			if err != nil {
+				t.Fatalf("encoding cursor: %v", err)
+			}
+			_, err = decodePageCursor(token, sort)
+			if test.want == "" && err != nil {
+				t.Errorf("decodePageCursor returned error: %v", err)
+			}
+			if test.want != "" && (err == nil || err.Error() != test.want) {
+				t.Errorf("decodePageCursor returned %v, want %q", err, test.want)
+			}
+		})
+	}
+
+	if _, err := decodePageCursor("not base64!", sort); err == nil || err.Error() != "cursor is malformed" {
+		t.Errorf("decodePageCursor on a bad token returned %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	helper "task-manager/server/helpers"
+	model "task-manager/server/models"
+)
+
+// parseTimeParam - Parses an RFC 3339 timestamp or a YYYY-MM-DD date from the query string
+func parseTimeParam(c *gin.Context, key string) (*time.Time, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		t = t.UTC()
+		return &t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, raw); err == nil {
+		return &t, nil
+	}
+	return nil, fmt.Errorf("%s must be an RFC 3339 timestamp or a YYYY-MM-DD date", key)
+}
+
+// parseBoolParam - Parses an optional boolean flag from the query string
+func parseBoolParam(c *gin.Context, key string) (bool, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return false, nil
+	}
+
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false", key)
+	}
+	return value, nil
+}
+
+// buildTaskFilter - Builds the Mongo filter for GetTasks from the query string, within the tasks matched by access
+func buildTaskFilter(c *gin.Context, userID string, access bson.M) (bson.M, error) {
+	now := time.Now().UTC()
+	conditions := []bson.M{helper.NotDeleted(access)}
+
+	// assigned_to=me and created_by=me narrow the listing to the tasks handed to or created by the user
+	if assignee := c.Query("assigned_to"); assignee == "me" {
+		conditions = append(conditions, assignedToFilter(userID))
+	} else if assignee != "" {
+		return nil, fmt.Errorf("assigned_to must be me")
+	}
+	if creator := c.Query("created_by"); creator == "me" {
+		conditions = append(conditions, bson.M{"user_id": userID})
+	} else if creator != "" {
+		return nil, fmt.Errorf("created_by must be me")
+	}
+
+	// project_id=inbox lists tasks that are not filed under any project
+	if project := c.Query("project_id"); project == "inbox" {
+		conditions = append(conditions, bson.M{"project_id": bson.M{"$exists": false}})
+	} else if project != "" {
+		projectID, err := primitive.ObjectIDFromHex(project)
+		if err != nil {
+			return nil, fmt.Errorf("project_id must be a valid ID or inbox")
+		}
+		conditions = append(conditions, bson.M{"project_id": projectID})
+	}
+
+	// tags=a,b matches tasks with any of the tags, or all of them with tag_mode=all
+	if rawTags := c.Query("tags"); rawTags != "" {
+		tags := normalizeTags(strings.Split(rawTags, ","))
+		if len(tags) == 0 {
+			return nil, fmt.Errorf("tags must name at least one tag")
+		}
+		switch c.DefaultQuery("tag_mode", "any") {
+		case "any":
+			conditions = append(conditions, bson.M{"tags": bson.M{"$in": tags}})
+		case "all":
+			conditions = append(conditions, bson.M{"tags": bson.M{"$all": tags}})
+		default:
+			return nil, fmt.Errorf("tag_mode must be any or all")
+		}
+	}
+
+	dueBefore, err := parseTimeParam(c, "due_before")
+	if err != nil {
+		return nil, err
+	}
+	if dueBefore != nil {
+		conditions = append(conditions, bson.M{"due_at": bson.M{"$lt": *dueBefore}})
+	}
+
+	dueAfter, err := parseTimeParam(c, "due_after")
+	if err != nil {
+		return nil, err
+	}
+	if dueAfter != nil {
+		conditions = append(conditions, bson.M{"due_at": bson.M{"$gt": *dueAfter}})
+	}
+
+	overdue, err := parseBoolParam(c, "overdue")
+	if err != nil {
+		return nil, err
+	}
+	if overdue {
+		conditions = append(conditions, bson.M{"due_at": bson.M{"$lt": now}, "status": bson.M{"$nin": model.ClosedStatuses}})
+	}
+
+	// filter=<expression> adds conditions written in the filter language, see parseTaskFilter
+	if expression := c.Query("filter"); expression != "" {
+		condition, err := parseTaskFilter(expression, now)
+		if err != nil {
+			return nil, err
+		}
+		if len(condition) > 0 {
+			conditions = append(conditions, condition)
+		}
+	}
+
+	// Tasks scheduled to start in the future stay hidden unless explicitly requested
+	includeFuture, err := parseBoolParam(c, "include_future")
+	if err != nil {
+		return nil, err
+	}
+	if !includeFuture {
+		conditions = append(conditions, bson.M{"start_at": bson.M{"$not": bson.M{"$gt": now}}})
+	}
+
+	if len(conditions) == 1 {
+		return conditions[0], nil
+	}
+	return bson.M{"$and": conditions}, nil
+}
+
+// sortableTaskFields - The allowlist of fields GetTasks can be sorted by
+var sortableTaskFields = map[string]bool{
+	"rank":         true,
+	"priority":     true,
+	"status":       true,
+	"title":        true,
+	"due_at":       true,
+	"start_at":     true,
+	"completed_at": true,
+	"created_at":   true,
+	"updated_at":   true,
+}
+
+const maxSortKeys = 4
+
+// parseTaskSort - Parses a sort parameter such as "-priority,due_at,created_at".
+// A leading "-" sorts that key descending. The task ID is always appended as a tie-breaker.
+func parseTaskSort(raw string) (bson.D, error) {
+	if raw == "" {
+		raw = "-created_at"
+	}
+
+	keys := strings.Split(raw, ",")
+	if len(keys) > maxSortKeys {
+		return nil, fmt.Errorf("sort accepts at most %d keys", maxSortKeys)
+	}
+
+	sort := bson.D{}
+	seen := map[string]bool{}
+	direction := 1
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		direction = 1
+		if strings.HasPrefix(key, "-") {
+			direction = -1
+			key = key[1:]
+		}
+
+		if !sortableTaskFields[key] {
+			return nil, fmt.Errorf("cannot sort by %q", key)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("sort key %q is repeated", key)
+		}
+		seen[key] = true
+		sort = append(sort, bson.E{Key: key, Value: direction})
+	}
+
+	return append(sort, bson.E{Key: "_id", Value: direction}), nil
+}
+
+// validateTaskDates - Ensures a task is not due before it starts
+func validateTaskDates(startAt, dueAt *time.Time) error {
+	if startAt != nil && dueAt != nil && dueAt.Before(*startAt) {
+		return fmt.Errorf("due_at cannot be before start_at")
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	database "task-manager/server/database"
+	helper "task-manager/server/helpers"
+	model "task-manager/server/models"
+)
+
+// findTrashedTask - Loads a task of the user that is in the trash
+func findTrashedTask(ctx context.Context, userID string, id primitive.ObjectID) (model.Task, error) {
+	var task model.Task
+	err := database.GetTaskCollection().FindOne(ctx, helper.InWorkspace(ctx, bson.M{"_id": id, "user_id": userID, "deleted_at": bson.M{"$ne": nil}})).Decode(&task)
+	if err == mongo.ErrNoDocuments {
+		return task, helper.NewRequestError(http.StatusNotFound, "Task not found", "No task in the trash for the specified ID and user")
+	}
+	return task, err
+}
+
+// trashedWith - Returns the IDs of a trashed task and of the subtasks that were trashed together with it
+func trashedWith(ctx context.Context, userID string, task model.Task) ([]primitive.ObjectID, error) {
+	descendants, err := lookupDescendants(ctx,
+		bson.M{"_id": task.ID, "user_id": userID},
+		bson.M{"user_id": userID, "deleted_at": *task.DeletedAt},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return append([]primitive.ObjectID{task.ID}, taskIDs(descendants)...), nil
+}
+
+// restoreTask - Takes a task and the subtasks trashed with it out of the trash.
+// If its parent or project no longer exists, the task is restored to the top level or the inbox.
+func restoreTask(ctx context.Context, userID string, id primitive.ObjectID) (model.Task, error) {
+	task, err := findTrashedTask(ctx, userID, id)
+	if err != nil {
+		return task, err
+	}
+	ids, err := trashedWith(ctx, userID, task)
+	if err != nil {
+		return task, err
+	}
+
+	collection := database.GetTaskCollection()
+	var reqErr *helper.RequestError
+	if task.ParentID != nil {
+		if _, err := findTask(ctx, userID, *task.ParentID); errors.As(err, &reqErr) {
+			if _, err := collection.UpdateOne(ctx, helper.InWorkspace(ctx, bson.M{"_id": id}), bson.M{"$unset": bson.M{"parent_id": ""}}); err != nil {
+				return task, err
+			}
+		} else if err != nil {
+			return task, err
+		}
+	}
+	if task.ProjectID != nil {
+		if _, err := findProject(ctx, userID, *task.ProjectID); errors.As(err, &reqErr) {
+			_, err := collection.UpdateMany(ctx,
+				helper.InWorkspace(ctx, bson.M{"_id": bson.M{"$in": ids}, "project_id": *task.ProjectID}),
+				bson.M{"$unset": bson.M{"project_id": ""}},
+			)
+			if err != nil {
+				return task, err
+			}
+		} else if err != nil {
+			return task, err
+		}
+	}
+
+	_, err = collection.UpdateMany(ctx,
+		helper.InWorkspace(ctx, bson.M{"_id": bson.M{"$in": ids}, "user_id": userID, "deleted_at": *task.DeletedAt}),
+		bson.M{"$unset": bson.M{"deleted_at": ""}},
+	)
+	if err != nil {
+		return task, err
+	}
+
+	// Reminders were cancelled when the tasks were trashed
+	if err := rescheduleReminders(ctx, userID, ids); err != nil {
+		return task, err
+	}
+	if err := syncDependents(ctx, ids); err != nil {
+		return task, err
+	}
+
+	return findTask(ctx, userID, id)
+}
+
+// rescheduleReminders - Schedules the reminders of restored tasks again
+func rescheduleReminders(ctx context.Context, userID string, ids []primitive.ObjectID) error {
+	cursor, err := database.GetTaskCollection().Find(ctx, helper.NotDeleted(helper.InWorkspace(ctx, bson.M{"_id": bson.M{"$in": ids}, "user_id": userID})))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var restored model.Task
+		if err := cursor.Decode(&restored); err != nil {
+			return err
+		}
+		if err := helper.ScheduleReminders(ctx, restored); err != nil {
+			log.Printf("Error scheduling reminders for task %s: %v", restored.ID.Hex(), err)
+		}
+	}
+	return cursor.Err()
+}
+
+// GetTrash - Retrieves a page of the user's trashed tasks, most recently deleted first
+func GetTrash() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		limit, err := parsePageSize(c)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		filter := helper.InWorkspace(ctx, bson.M{"user_id": userID, "deleted_at": bson.M{"$ne": nil}})
+		sort := bson.D{{Key: "deleted_at", Value: -1}, {Key: "_id", Value: -1}}
+		page, err := findTaskPage(ctx, database.GetTaskCollection(), filter, sort, limit, c.Query("cursor"))
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching trash")
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Trash for "+username, page)
+	}
+}
+
+// RestoreTask - Takes a task, and the subtasks deleted along with it, out of the trash
+func RestoreTask() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		task, err := restoreTask(ctx, userID, id)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error restoring task")
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Task restored successfully for "+username, task)
+	}
+}
+
+// PurgeTask - Permanently deletes a trashed task and the subtasks deleted along with it
+func PurgeTask() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		task, err := findTrashedTask(ctx, userID, id)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching task")
+			return
+		}
+		ids, err := trashedWith(ctx, userID, task)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error fetching subtasks", err.Error())
+			return
+		}
+
+		if _, err := helper.PurgeTasks(ctx, helper.InWorkspace(ctx, bson.M{"_id": bson.M{"$in": ids}, "user_id": userID})); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error purging task", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Task permanently deleted", nil)
+	}
+}
+
+// EmptyTrash - Permanently deletes every task in the user's trash
+func EmptyTrash() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID not found in context")
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		purged, err := helper.PurgeTasks(ctx, helper.InWorkspace(ctx, bson.M{"user_id": userID}))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error emptying trash", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Trash emptied successfully", gin.H{"purged": purged})
+	}
+}
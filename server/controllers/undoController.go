@@ -0,0 +1,401 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	database "task-manager/server/database"
+	helper "task-manager/server/helpers"
+	model "task-manager/server/models"
+)
+
+// maxOperationLog - How many operations are kept in a user's undo log; older ones can no longer be undone
+const maxOperationLog = 100
+
+// maxUndoCount - The most operations one undo or redo request may reverse or replay
+const maxUndoCount = 20
+
+// operationConflict - The error for an operation whose tasks changed after it was made
+func operationConflict(details string) error {
+	return helper.NewRequestError(http.StatusConflict, "Operation conflicts with later changes", details)
+}
+
+// logOperation - Adds an operation to the user's undo log for the workspace. A new operation discards the operations
+// that were undone but not redone, and the log is trimmed to its newest maxOperationLog entries.
+// Failures are logged rather than failing the operation itself.
+func logOperation(ctx context.Context, op model.Operation) {
+	collection := database.GetOperationCollection()
+	op.ID = primitive.NewObjectID()
+	op.Created = time.Now().UTC()
+	op.WorkspaceID, _, _ = helper.WorkspaceFromContext(ctx)
+
+	if _, err := collection.DeleteMany(ctx, helper.InWorkspace(ctx, bson.M{"user_id": op.UserID, "undone": true})); err != nil {
+		log.Printf("Error clearing redo log for user %s: %v", op.UserID, err)
+		return
+	}
+	if _, err := collection.InsertOne(ctx, op); err != nil {
+		log.Printf("Error logging operation for user %s: %v", op.UserID, err)
+		return
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(maxOperationLog).
+		SetProjection(bson.M{"_id": 1})
+	cursor, err := collection.Find(ctx, helper.InWorkspace(ctx, bson.M{"user_id": op.UserID}), opts)
+	if err != nil {
+		log.Printf("Error trimming operation log for user %s: %v", op.UserID, err)
+		return
+	}
+	var expired []model.Operation
+	if err := cursor.All(ctx, &expired); err != nil || len(expired) == 0 {
+		return
+	}
+	ids := make([]primitive.ObjectID, len(expired))
+	for i, old := range expired {
+		ids[i] = old.ID
+	}
+	if _, err := collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+		log.Printf("Error trimming operation log for user %s: %v", op.UserID, err)
+	}
+}
+
+// liveTaskAt - Loads the task of an operation, which must not have been updated since stamp
+func liveTaskAt(ctx context.Context, userID string, id primitive.ObjectID, stamp time.Time) (model.Task, error) {
+	task, err := findTask(ctx, userID, id)
+	var reqErr *helper.RequestError
+	if errors.As(err, &reqErr) {
+		return task, operationConflict("Task " + id.Hex() + " has since been deleted")
+	} else if err != nil {
+		return task, err
+	}
+	if !task.Updated.Equal(stamp) {
+		return task, operationConflict("Task " + id.Hex() + " has been changed since")
+	}
+	return task, nil
+}
+
+// trashedTaskAt - Loads the task of an operation, which must still be in the trash from the deletion at stamp
+func trashedTaskAt(ctx context.Context, userID string, id primitive.ObjectID, stamp time.Time) (model.Task, error) {
+	task, err := findTrashedTask(ctx, userID, id)
+	var reqErr *helper.RequestError
+	if errors.As(err, &reqErr) {
+		return task, operationConflict("Task " + id.Hex() + " has since been restored or purged")
+	} else if err != nil {
+		return task, err
+	}
+	if !task.DeletedAt.Equal(stamp) {
+		return task, operationConflict("Task " + id.Hex() + " has been deleted again since")
+	}
+	return task, nil
+}
+
+// updateChanges - The field changes that take a task from one snapshot to another
+func updateChanges(from, to model.Task) ([]model.FieldChange, error) {
+	fromDoc, err := taskDocument(from)
+	if err != nil {
+		return nil, err
+	}
+	toDoc, err := taskDocument(to)
+	if err != nil {
+		return nil, err
+	}
+	return diffTaskDocuments(fromDoc, toDoc), nil
+}
+
+// reapplyUpdate - Moves the task of an update operation from one of its snapshots to the other
+func reapplyUpdate(ctx context.Context, userID string, username string, op *model.Operation, from, to model.Task) error {
+	current, err := liveTaskAt(ctx, op.Owner(), *op.TaskID, op.Stamp)
+	if err != nil {
+		return err
+	}
+	if err := requireTaskRole(ctx, userID, current, model.RoleEditor); err != nil {
+		return err
+	}
+	changes, err := updateChanges(from, to)
+	if err != nil || len(changes) == 0 {
+		return err
+	}
+	task, err := applyTaskChanges(ctx, userID, username, current, changes, model.RevisionUpdate, 0)
+	if err != nil {
+		return err
+	}
+	op.Stamp = task.Updated
+	return nil
+}
+
+// checkClosingSideEffects - Refuses to undo an update that closed a task when closing it went on to close its
+// subtasks or schedule the next occurrence of its series, as reverting the task alone would leave those behind
+func checkClosingSideEffects(ctx context.Context, ownerID string, op *model.Operation) error {
+	if op.Before.Status == op.After.Status || !op.After.Status.IsClosed() {
+		return nil
+	}
+	task, err := liveTaskAt(ctx, ownerID, *op.TaskID, op.Stamp)
+	if err != nil {
+		return err
+	}
+	if op.Before.NextOccurrenceID == nil && task.NextOccurrenceID != nil {
+		return operationConflict("Completing task " + task.ID.Hex() + " scheduled its next occurrence")
+	}
+
+	descendants, err := findDescendants(ctx, ownerID, task.ID)
+	if err != nil {
+		return err
+	}
+	for _, descendant := range descendants {
+		// Subtasks closed along with the task share its status and update time
+		if descendant.Status == task.Status && descendant.Updated.Equal(op.Stamp) {
+			return operationConflict("Closing task " + task.ID.Hex() + " also closed its subtasks")
+		}
+	}
+	return nil
+}
+
+// undoOperation - Reverses an operation, updating its stamp so that it can be redone
+func undoOperation(ctx context.Context, userID string, username string, op *model.Operation) error {
+	ownerID := op.Owner()
+	switch op.Action {
+	case model.OperationCreate:
+		if _, err := liveTaskAt(ctx, ownerID, *op.TaskID, op.Stamp); err != nil {
+			return err
+		}
+		deletion, err := moveToTrash(ctx, ownerID, *op.TaskID, "delete")
+		op.Stamp = deletion.DeletedAt
+		return err
+
+	case model.OperationUpdate:
+		if err := checkClosingSideEffects(ctx, ownerID, op); err != nil {
+			return err
+		}
+		return reapplyUpdate(ctx, userID, username, op, *op.After, *op.Before)
+
+	case model.OperationDelete:
+		trashed, err := trashedTaskAt(ctx, ownerID, *op.TaskID, op.Stamp)
+		if err != nil {
+			return err
+		}
+		if err := requireTaskRole(ctx, userID, trashed, model.RoleOwner); err != nil {
+			return err
+		}
+		restored, err := restoreTask(ctx, ownerID, *op.TaskID)
+		if err != nil {
+			return err
+		}
+		if len(op.Promoted) > 0 {
+			_, err = database.GetTaskCollection().UpdateMany(ctx,
+				helper.NotDeleted(helper.InWorkspace(ctx, bson.M{"_id": bson.M{"$in": op.Promoted}, "user_id": ownerID, "parent_id": trashed.ParentID})),
+				bson.M{"$set": bson.M{"parent_id": *op.TaskID}},
+			)
+		}
+		op.Stamp = restored.Updated
+		return err
+
+	case model.OperationDeleteAll:
+		collection := database.GetTaskCollection()
+		filter := helper.InWorkspace(ctx, bson.M{"user_id": ownerID, "deleted_at": op.Stamp})
+		cursor, err := collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+		if err != nil {
+			return err
+		}
+		var tasks []model.Task
+		if err := cursor.All(ctx, &tasks); err != nil {
+			return err
+		}
+		if len(tasks) == 0 {
+			return operationConflict("The deleted tasks have since been restored or purged")
+		}
+
+		op.TaskIDs = taskIDs(tasks)
+		filter["_id"] = bson.M{"$in": op.TaskIDs}
+		if _, err := collection.UpdateMany(ctx, filter, bson.M{"$unset": bson.M{"deleted_at": ""}}); err != nil {
+			return err
+		}
+		if err := rescheduleReminders(ctx, ownerID, op.TaskIDs); err != nil {
+			return err
+		}
+		return syncDependents(ctx, op.TaskIDs)
+	}
+	return errors.New("unknown operation " + string(op.Action))
+}
+
+// redoOperation - Replays an undone operation, updating its stamp so that it can be undone again
+func redoOperation(ctx context.Context, userID string, username string, op *model.Operation) error {
+	ownerID := op.Owner()
+	switch op.Action {
+	case model.OperationCreate:
+		if _, err := trashedTaskAt(ctx, ownerID, *op.TaskID, op.Stamp); err != nil {
+			return err
+		}
+		restored, err := restoreTask(ctx, ownerID, *op.TaskID)
+		op.Stamp = restored.Updated
+		return err
+
+	case model.OperationUpdate:
+		return reapplyUpdate(ctx, userID, username, op, *op.Before, *op.After)
+
+	case model.OperationDelete:
+		live, err := liveTaskAt(ctx, ownerID, *op.TaskID, op.Stamp)
+		if err != nil {
+			return err
+		}
+		if err := requireTaskRole(ctx, userID, live, model.RoleOwner); err != nil {
+			return err
+		}
+		deletion, err := moveToTrash(ctx, ownerID, *op.TaskID, op.Children)
+		op.Stamp, op.Promoted = deletion.DeletedAt, deletion.Promoted
+		return err
+
+	case model.OperationDeleteAll:
+		deletedAt := time.Now().UTC().Truncate(time.Millisecond)
+		result, err := database.GetTaskCollection().UpdateMany(ctx,
+			helper.NotDeleted(helper.InWorkspace(ctx, bson.M{"_id": bson.M{"$in": op.TaskIDs}, "user_id": ownerID})),
+			bson.M{"$set": bson.M{"deleted_at": deletedAt}},
+		)
+		if err != nil {
+			return err
+		}
+		if result.ModifiedCount == 0 {
+			return operationConflict("The restored tasks have since been deleted again")
+		}
+		if err := helper.CancelReminders(ctx, op.TaskIDs); err != nil {
+			log.Printf("Error cancelling reminders for user %s: %v", ownerID, err)
+		}
+		if err := syncDependents(ctx, op.TaskIDs); err != nil {
+			log.Printf("Error unblocking dependents for user %s: %v", ownerID, err)
+		}
+		op.Stamp, op.TaskIDs = deletedAt, nil
+		return nil
+	}
+	return errors.New("unknown operation " + string(op.Action))
+}
+
+// replayOperations - Undoes the user's count most recent operations, newest first, or with undo unset
+// redoes the count oldest undone ones, oldest first. Each operation is claimed before it is applied so
+// concurrent requests cannot apply it twice. Stops at the first operation that fails and returns the
+// operations applied until then along with the error.
+func replayOperations(ctx context.Context, userID string, username string, undo bool, count int) ([]model.Operation, error) {
+	collection := database.GetOperationCollection()
+	direction := -1
+	if !undo {
+		direction = 1
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: direction}, {Key: "_id", Value: direction}}).SetLimit(int64(count))
+	cursor, err := collection.Find(ctx, helper.InWorkspace(ctx, bson.M{"user_id": userID, "undone": !undo}), opts)
+	if err != nil {
+		return nil, err
+	}
+	var pending []model.Operation
+	if err := cursor.All(ctx, &pending); err != nil {
+		return nil, err
+	}
+	if len(pending) == 0 {
+		if undo {
+			return nil, helper.NewRequestError(http.StatusNotFound, "Nothing to undo", "There are no operations left to undo")
+		}
+		return nil, helper.NewRequestError(http.StatusNotFound, "Nothing to redo", "There are no undone operations to redo")
+	}
+
+	applied := []model.Operation{}
+	for _, op := range pending {
+		claim, err := collection.UpdateOne(ctx, bson.M{"_id": op.ID, "undone": !undo}, bson.M{"$set": bson.M{"undone": undo}})
+		if err != nil {
+			return applied, err
+		}
+		if claim.ModifiedCount == 0 {
+			return applied, operationConflict("The operation was undone or redone by another request")
+		}
+
+		if undo {
+			err = undoOperation(ctx, userID, username, &op)
+		} else {
+			err = redoOperation(ctx, userID, username, &op)
+		}
+		if err != nil {
+			if _, releaseErr := collection.UpdateOne(ctx, bson.M{"_id": op.ID}, bson.M{"$set": bson.M{"undone": !undo}}); releaseErr != nil {
+				log.Printf("Error releasing operation %s: %v", op.ID.Hex(), releaseErr)
+			}
+			return applied, err
+		}
+
+		op.Undone = undo
+		_, err = collection.UpdateOne(ctx, bson.M{"_id": op.ID}, bson.M{"$set": bson.M{
+			"stamp":    op.Stamp,
+			"task_ids": op.TaskIDs,
+			"promoted": op.Promoted,
+		}})
+		if err != nil {
+			return applied, err
+		}
+		applied = append(applied, op)
+	}
+	return applied, nil
+}
+
+// respondWithReplay - Handles undo and redo requests: ?count= sets how many operations to apply, 1 by default.
+// When an operation conflicts, the conflict is reported as an error whose details list the operations applied before it.
+func respondWithReplay(c *gin.Context, undo bool) {
+	userID, username, valid := helper.GetUserDetails(c)
+	if !valid {
+		helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+		return
+	}
+
+	count := 1
+	if raw := c.Query("count"); raw != "" {
+		var err error
+		if count, err = strconv.Atoi(raw); err != nil || count < 1 || count > maxUndoCount {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid query parameters", "count must be between 1 and "+strconv.Itoa(maxUndoCount))
+			return
+		}
+	}
+
+	verb := "Redid"
+	if undo {
+		verb = "Undid"
+	}
+
+	ctx, cancel := getContextWithTimeout(c)
+	defer cancel()
+
+	applied, err := replayOperations(ctx, userID, username, undo, count)
+	if err != nil {
+		var reqErr *helper.RequestError
+		if len(applied) > 0 && errors.As(err, &reqErr) {
+			ids := make([]string, len(applied))
+			for i, op := range applied {
+				ids[i] = op.ID.Hex()
+			}
+			helper.RespondWithError(c, reqErr.Code, reqErr.Message, verb+" "+strconv.Itoa(len(applied))+" operations ("+strings.Join(ids, ", ")+") before stopping: "+reqErr.Details)
+			return
+		}
+		helper.RespondWithRequestError(c, err, "Error applying operations")
+		return
+	}
+
+	helper.RespondWithSuccess(c, http.StatusOK, verb+" "+strconv.Itoa(len(applied))+" operations for "+username, applied)
+}
+
+// Undo - Reverses the user's most recent task operations
+func Undo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondWithReplay(c, true)
+	}
+}
+
+// Redo - Replays the task operations the user most recently undid
+func Redo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondWithReplay(c, false)
+	}
+}
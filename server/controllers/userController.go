@@ -15,8 +15,6 @@ import (
 	model "task-manager/server/models"
 )
 
-var userCollection *mongo.Collection = database.GetUserCollection()
-
 // GetUsers - Responds with the list of all users as JSON
 func GetUsers() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -58,7 +56,7 @@ func GetUsers() gin.HandlerFunc {
 				{Key: "total_count", Value: 1},
 				{Key: "user_items", Value: bson.D{{Key: "$slice", Value: []interface{}{"$data", startIndex, recordPerPage}}}}}}}
 
-		result, err := userCollection.Aggregate(ctx, mongo.Pipeline{matchStage, groupStage, projectStage})
+		result, err := database.GetUserCollection().Aggregate(ctx, mongo.Pipeline{matchStage, groupStage, projectStage})
 		if err != nil {
 			helper.RespondWithError(c, http.StatusInternalServerError, "Database error", err.Error())
 			return
@@ -94,7 +92,7 @@ func GetUser() gin.HandlerFunc {
 		defer cancel()
 
 		var user model.User
-		err := userCollection.FindOne(ctx, bson.M{"userid": UserID}).Decode(&user)
+		err := database.GetUserCollection().FindOne(ctx, bson.M{"userid": UserID}).Decode(&user)
 		if err != nil {
 			helper.RespondWithError(c, http.StatusInternalServerError, "User not found", err.Error())
 			return
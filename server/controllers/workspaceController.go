@@ -0,0 +1,569 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	database "task-manager/server/database"
+	helper "task-manager/server/helpers"
+	model "task-manager/server/models"
+)
+
+// requireWorkspaceRole - Ensures the user has at least need role in the workspace of the request
+func requireWorkspaceRole(ctx context.Context, need model.WorkspaceRole) error {
+	_, role, ok := helper.WorkspaceFromContext(ctx)
+	if !ok || !role.Allows(need) {
+		return helper.NewRequestError(http.StatusForbidden, "Not allowed", "This needs the "+string(need)+" role in the workspace")
+	}
+	return nil
+}
+
+// findManagedWorkspace - Loads a workspace whose settings or members the user wants to change, checking they have
+// at least need role in it. Personal workspaces only ever hold their owner.
+func findManagedWorkspace(ctx context.Context, userID string, id primitive.ObjectID, need model.WorkspaceRole) (model.Workspace, model.Membership, error) {
+	var workspace model.Workspace
+	membership, err := helper.FindMembership(ctx, id, userID)
+	if err != nil {
+		return workspace, membership, err
+	}
+	if err := database.GetWorkspaceCollection().FindOne(ctx, bson.M{"_id": id}).Decode(&workspace); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return workspace, membership, helper.NewRequestError(http.StatusNotFound, "Workspace not found", "No workspace found for the specified ID and user")
+		}
+		return workspace, membership, err
+	}
+	if !membership.Role.Allows(need) {
+		return workspace, membership, helper.NewRequestError(http.StatusForbidden, "Not allowed", "This needs the "+string(need)+" role in the workspace")
+	}
+	return workspace, membership, nil
+}
+
+// checkWorkspaceMember - Ensures a user being given a task belongs to the workspace, so nothing is handed across workspaces.
+// Outsiders can join team workspaces as guests. Personal workspaces take no members, the users their tasks are shared
+// with or assigned to reach them as guests instead.
+func checkWorkspaceMember(ctx context.Context, workspaceID primitive.ObjectID, userID string) error {
+	_, err := helper.FindMembership(ctx, workspaceID, userID)
+	var reqErr *helper.RequestError
+	if !errors.As(err, &reqErr) {
+		return err
+	}
+	personal, err := helper.IsPersonalWorkspace(ctx, workspaceID)
+	if err != nil || personal {
+		return err
+	}
+	return helper.NewRequestError(http.StatusBadRequest, "Not a workspace member", "Add the user to the workspace, as a guest if need be, first")
+}
+
+// countOwners - Counts the owners of a workspace, so the last one cannot leave or be demoted
+func countOwners(ctx context.Context, id primitive.ObjectID) (int64, error) {
+	return database.GetMembershipCollection().CountDocuments(ctx, bson.M{"workspace_id": id, "role": model.WorkspaceOwner})
+}
+
+// checkRoleChange - Ensures the user may give or take away the role: only owners deal with owners
+func checkRoleChange(actor model.Membership, role model.WorkspaceRole) error {
+	if role == model.WorkspaceOwner && actor.Role != model.WorkspaceOwner {
+		return helper.NewRequestError(http.StatusForbidden, "Not allowed", "Only owners can make or change other owners")
+	}
+	return nil
+}
+
+// GetWorkspaces - Lists the workspaces the user belongs to, starting with their personal one
+func GetWorkspaces() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		if _, err := helper.EnsurePersonalWorkspace(ctx, userID, username); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error fetching workspaces", err.Error())
+			return
+		}
+
+		cursor, err := database.GetMembershipCollection().Find(ctx, bson.M{"user_id": userID})
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error fetching memberships", err.Error())
+			return
+		}
+		var memberships []model.Membership
+		if err := cursor.All(ctx, &memberships); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error decoding memberships", err.Error())
+			return
+		}
+
+		roles := map[primitive.ObjectID]model.WorkspaceRole{}
+		ids := make([]primitive.ObjectID, 0, len(memberships))
+		for _, membership := range memberships {
+			roles[membership.WorkspaceID] = membership.Role
+			ids = append(ids, membership.WorkspaceID)
+		}
+		// Other users' personal workspaces are listed too when they shared or assigned something there
+		guestIDs, err := helper.GuestWorkspaceIDs(ctx, userID)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error fetching shared workspaces", err.Error())
+			return
+		}
+		for _, id := range guestIDs {
+			roles[id] = model.WorkspaceGuest
+			ids = append(ids, id)
+		}
+
+		opts := options.Find().SetSort(bson.D{{Key: "personal", Value: -1}, {Key: "name", Value: 1}})
+		cursor, err = database.GetWorkspaceCollection().Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, opts)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error fetching workspaces", err.Error())
+			return
+		}
+		var workspaces []model.Workspace
+		if err := cursor.All(ctx, &workspaces); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error decoding workspaces", err.Error())
+			return
+		}
+
+		views := make([]model.WorkspaceView, len(workspaces))
+		for i, workspace := range workspaces {
+			views[i] = model.WorkspaceView{Workspace: workspace, Role: roles[workspace.ID]}
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Workspaces for "+username, views)
+	}
+}
+
+// PostWorkspace - Creates a team workspace with the user as its owner
+func PostWorkspace() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		var request struct {
+			Name string `json:"name" validate:"required,min=1,max=100"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid JSON input", err.Error())
+			return
+		}
+		if err := validate.Struct(request); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Validation error", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		now := time.Now().UTC()
+		workspace := model.Workspace{
+			ID:      primitive.NewObjectID(),
+			Name:    request.Name,
+			OwnerID: userID,
+			Created: now,
+			Updated: now,
+		}
+		membership := model.Membership{
+			ID:          primitive.NewObjectID(),
+			WorkspaceID: workspace.ID,
+			UserID:      userID,
+			Username:    username,
+			Role:        model.WorkspaceOwner,
+			Created:     now,
+			Updated:     now,
+		}
+
+		err := database.WithTransaction(ctx, func(txCtx context.Context) error {
+			if _, err := database.GetWorkspaceCollection().InsertOne(txCtx, workspace); err != nil {
+				return err
+			}
+			_, err := database.GetMembershipCollection().InsertOne(txCtx, membership)
+			return err
+		})
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error creating workspace", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusCreated, "Workspace created successfully", model.WorkspaceView{Workspace: workspace, Role: membership.Role})
+	}
+}
+
+// UpdateWorkspace - Renames a workspace. Needs the admin role.
+func UpdateWorkspace() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("workspace_id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		var request struct {
+			Name string `json:"name" validate:"required,min=1,max=100"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid JSON input", err.Error())
+			return
+		}
+		if err := validate.Struct(request); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Validation error", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		_, membership, err := findManagedWorkspace(ctx, userID, id, model.WorkspaceAdmin)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching workspace")
+			return
+		}
+
+		var workspace model.Workspace
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		err = database.GetWorkspaceCollection().FindOneAndUpdate(ctx,
+			bson.M{"_id": id},
+			bson.M{"$set": bson.M{"name": request.Name, "updated_at": time.Now().UTC()}},
+			opts,
+		).Decode(&workspace)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error updating workspace", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Workspace updated successfully by "+username, model.WorkspaceView{Workspace: workspace, Role: membership.Role})
+	}
+}
+
+// DeleteWorkspace - Deletes an empty team workspace. Needs the owner role; tasks and projects
+// have to be moved out or purged first so nothing is lost by accident.
+func DeleteWorkspace() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("workspace_id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		workspace, _, err := findManagedWorkspace(ctx, userID, id, model.WorkspaceOwner)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching workspace")
+			return
+		}
+		if workspace.Personal {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid workspace", "Personal workspaces cannot be deleted")
+			return
+		}
+
+		tasks, err := database.GetTaskCollection().CountDocuments(ctx, bson.M{"workspace_id": id}, options.Count().SetLimit(1))
+		if err == nil && tasks == 0 {
+			tasks, err = database.GetProjectCollection().CountDocuments(ctx, bson.M{"workspace_id": id}, options.Count().SetLimit(1))
+		}
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error checking workspace", err.Error())
+			return
+		}
+		if tasks > 0 {
+			helper.RespondWithError(c, http.StatusConflict, "Workspace not empty", "Delete or purge the workspace's tasks and projects first")
+			return
+		}
+
+		if _, err := database.GetMembershipCollection().DeleteMany(ctx, bson.M{"workspace_id": id}); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error deleting workspace members", err.Error())
+			return
+		}
+		if _, err := database.GetWorkspaceCollection().DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error deleting workspace", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Workspace deleted successfully", nil)
+	}
+}
+
+// GetMembers - Lists the members of a workspace the user belongs to
+func GetMembers() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("workspace_id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		if _, _, err := findManagedWorkspace(ctx, userID, id, model.WorkspaceGuest); err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching workspace")
+			return
+		}
+
+		opts := options.Find().SetSort(bson.D{{Key: "username", Value: 1}})
+		cursor, err := database.GetMembershipCollection().Find(ctx, bson.M{"workspace_id": id}, opts)
+		if err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error fetching members", err.Error())
+			return
+		}
+		defer cursor.Close(ctx)
+
+		members := []model.Membership{}
+		if err := cursor.All(ctx, &members); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error decoding members", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Workspace members for "+username, members)
+	}
+}
+
+// AddMember - Adds a user, identified by user_id or username, to a team workspace. Needs the admin role.
+func AddMember() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("workspace_id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		var request struct {
+			UserID   string              `json:"user_id"`
+			Username string              `json:"username"`
+			Role     model.WorkspaceRole `json:"role" validate:"required,oneof=owner admin member guest"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid JSON input", err.Error())
+			return
+		}
+		if err := validate.Struct(request); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Validation error", err.Error())
+			return
+		}
+		if (request.UserID == "") == (request.Username == "") {
+			helper.RespondWithError(c, http.StatusBadRequest, "Validation error", "Give exactly one of user_id or username")
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		workspace, actor, err := findManagedWorkspace(ctx, userID, id, model.WorkspaceAdmin)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching workspace")
+			return
+		}
+		if workspace.Personal {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid workspace", "Personal workspaces cannot have other members, create a team workspace instead")
+			return
+		}
+		if err := checkRoleChange(actor, request.Role); err != nil {
+			helper.RespondWithRequestError(c, err, "Error adding member")
+			return
+		}
+
+		user, err := findUser(ctx, request.UserID, request.Username)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching user")
+			return
+		}
+
+		now := time.Now().UTC()
+		membership := model.Membership{
+			ID:          primitive.NewObjectID(),
+			WorkspaceID: id,
+			UserID:      *user.UserID,
+			Username:    *user.Username,
+			Role:        request.Role,
+			Created:     now,
+			Updated:     now,
+		}
+		if _, err := database.GetMembershipCollection().InsertOne(ctx, membership); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				helper.RespondWithError(c, http.StatusConflict, "Already a member", membership.Username+" is already in the workspace, change their role instead")
+				return
+			}
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error adding member", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusCreated, "Member added successfully by "+username, membership)
+	}
+}
+
+// UpdateMember - Changes the role of a member of a workspace. Needs the admin role, and owners for anything involving owners.
+func UpdateMember() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("workspace_id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+
+		var request struct {
+			Role model.WorkspaceRole `json:"role" validate:"required,oneof=owner admin member guest"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid JSON input", err.Error())
+			return
+		}
+		if err := validate.Struct(request); err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Validation error", err.Error())
+			return
+		}
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		_, actor, err := findManagedWorkspace(ctx, userID, id, model.WorkspaceAdmin)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching workspace")
+			return
+		}
+		member, err := helper.FindMembership(ctx, id, c.Param("user_id"))
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching member")
+			return
+		}
+		if err := checkRoleChange(actor, member.Role); err == nil {
+			err = checkRoleChange(actor, request.Role)
+		}
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error updating member")
+			return
+		}
+		if member.Role == model.WorkspaceOwner && request.Role != model.WorkspaceOwner {
+			owners, err := countOwners(ctx, id)
+			if err != nil {
+				helper.RespondWithError(c, http.StatusInternalServerError, "Error counting owners", err.Error())
+				return
+			}
+			if owners <= 1 {
+				helper.RespondWithError(c, http.StatusConflict, "Last owner", "A workspace needs at least one owner, make someone else owner first")
+				return
+			}
+		}
+
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		err = database.GetMembershipCollection().FindOneAndUpdate(ctx,
+			bson.M{"_id": member.ID, "role": member.Role},
+			bson.M{"$set": bson.M{"role": request.Role, "updated_at": time.Now().UTC()}},
+			opts,
+		).Decode(&member)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				helper.RespondWithError(c, http.StatusConflict, "Member was modified concurrently", "The member's role changed while updating it, please retry")
+				return
+			}
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error updating member", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Member updated successfully by "+username, member)
+	}
+}
+
+// RemoveMember - Removes a member from a workspace. Needs the admin role, but anyone can leave on their own.
+func RemoveMember() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID not found in context")
+			return
+		}
+
+		id, err := primitive.ObjectIDFromHex(c.Param("workspace_id"))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid ID format", err.Error())
+			return
+		}
+		memberID := c.Param("user_id")
+
+		ctx, cancel := getContextWithTimeout(c)
+		defer cancel()
+
+		need := model.WorkspaceAdmin
+		if memberID == userID {
+			need = model.WorkspaceGuest
+		}
+		workspace, actor, err := findManagedWorkspace(ctx, userID, id, need)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching workspace")
+			return
+		}
+		if workspace.Personal {
+			helper.RespondWithError(c, http.StatusBadRequest, "Invalid workspace", "Nobody can leave their personal workspace")
+			return
+		}
+		member, err := helper.FindMembership(ctx, id, memberID)
+		if err != nil {
+			helper.RespondWithRequestError(c, err, "Error fetching member")
+			return
+		}
+		if memberID != userID {
+			if err := checkRoleChange(actor, member.Role); err != nil {
+				helper.RespondWithRequestError(c, err, "Error removing member")
+				return
+			}
+		}
+		if member.Role == model.WorkspaceOwner {
+			owners, err := countOwners(ctx, id)
+			if err != nil {
+				helper.RespondWithError(c, http.StatusInternalServerError, "Error counting owners", err.Error())
+				return
+			}
+			if owners <= 1 {
+				helper.RespondWithError(c, http.StatusConflict, "Last owner", "A workspace needs at least one owner, make someone else owner first")
+				return
+			}
+		}
+
+		if _, err := database.GetMembershipCollection().DeleteOne(ctx, bson.M{"_id": member.ID}); err != nil {
+			helper.RespondWithError(c, http.StatusInternalServerError, "Error removing member", err.Error())
+			return
+		}
+
+		helper.RespondWithSuccess(c, http.StatusOK, "Member removed successfully", nil)
+	}
+}
@@ -14,7 +14,9 @@ import (
 
 var MongoClient *mongo.Client
 
-func init() {
+// Connect - Loads the .env file and connects MongoClient, exiting when either fails.
+// The server calls it once at startup, before anything uses a collection.
+func Connect() {
 	if err := godotenv.Load(); err != nil {
 		log.Fatalf("Error loading .env file: %v", err)
 	}
@@ -68,3 +70,93 @@ func GetUserCollection() *mongo.Collection {
 	}
 	return MongoClient.Database("task_manager").Collection("users")
 }
+
+// GetProjectCollection retrieves the "projects" collection from the database.
+func GetProjectCollection() *mongo.Collection {
+	if MongoClient == nil {
+		log.Fatal("MongoDB client is not initialized. Ensure ConnectToMongoDB() is successful.")
+	}
+	return MongoClient.Database("task_manager").Collection("projects")
+}
+
+// GetTagCollection retrieves the "tags" collection from the database.
+func GetTagCollection() *mongo.Collection {
+	if MongoClient == nil {
+		log.Fatal("MongoDB client is not initialized. Ensure ConnectToMongoDB() is successful.")
+	}
+	return MongoClient.Database("task_manager").Collection("tags")
+}
+
+// GetReminderCollection retrieves the "reminders" collection from the database.
+func GetReminderCollection() *mongo.Collection {
+	if MongoClient == nil {
+		log.Fatal("MongoDB client is not initialized. Ensure ConnectToMongoDB() is successful.")
+	}
+	return MongoClient.Database("task_manager").Collection("reminders")
+}
+
+// GetSavedFilterCollection retrieves the "saved_filters" collection from the database.
+func GetSavedFilterCollection() *mongo.Collection {
+	if MongoClient == nil {
+		log.Fatal("MongoDB client is not initialized. Ensure ConnectToMongoDB() is successful.")
+	}
+	return MongoClient.Database("task_manager").Collection("saved_filters")
+}
+
+// GetBoardCollection retrieves the "boards" collection from the database.
+func GetBoardCollection() *mongo.Collection {
+	if MongoClient == nil {
+		log.Fatal("MongoDB client is not initialized. Ensure ConnectToMongoDB() is successful.")
+	}
+	return MongoClient.Database("task_manager").Collection("boards")
+}
+
+// GetRevisionCollection retrieves the "task_revisions" collection from the database.
+// Field values in revisions are untyped, so embedded documents are decoded as maps.
+func GetRevisionCollection() *mongo.Collection {
+	if MongoClient == nil {
+		log.Fatal("MongoDB client is not initialized. Ensure ConnectToMongoDB() is successful.")
+	}
+	opts := options.Collection().SetBSONOptions(&options.BSONOptions{DefaultDocumentM: true})
+	return MongoClient.Database("task_manager").Collection("task_revisions", opts)
+}
+
+// GetOperationCollection retrieves the "operations" collection from the database.
+func GetOperationCollection() *mongo.Collection {
+	if MongoClient == nil {
+		log.Fatal("MongoDB client is not initialized. Ensure ConnectToMongoDB() is successful.")
+	}
+	return MongoClient.Database("task_manager").Collection("operations")
+}
+
+// GetCommentCollection retrieves the "comments" collection from the database.
+func GetCommentCollection() *mongo.Collection {
+	if MongoClient == nil {
+		log.Fatal("MongoDB client is not initialized. Ensure ConnectToMongoDB() is successful.")
+	}
+	return MongoClient.Database("task_manager").Collection("comments")
+}
+
+// GetShareCollection retrieves the "shares" collection from the database.
+func GetShareCollection() *mongo.Collection {
+	if MongoClient == nil {
+		log.Fatal("MongoDB client is not initialized. Ensure ConnectToMongoDB() is successful.")
+	}
+	return MongoClient.Database("task_manager").Collection("shares")
+}
+
+// GetWorkspaceCollection retrieves the "workspaces" collection from the database.
+func GetWorkspaceCollection() *mongo.Collection {
+	if MongoClient == nil {
+		log.Fatal("MongoDB client is not initialized. Ensure ConnectToMongoDB() is successful.")
+	}
+	return MongoClient.Database("task_manager").Collection("workspaces")
+}
+
+// GetMembershipCollection retrieves the "workspace_members" collection from the database.
+func GetMembershipCollection() *mongo.Collection {
+	if MongoClient == nil {
+		log.Fatal("MongoDB client is not initialized. Ensure ConnectToMongoDB() is successful.")
+	}
+	return MongoClient.Database("task_manager").Collection("workspace_members")
+}
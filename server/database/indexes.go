@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnsureIndexes - Creates the indexes the queries rely on. Creating an existing index is a no-op.
+func EnsureIndexes(ctx context.Context) error {
+	indexes := map[*mongo.Collection][]mongo.IndexModel{
+		GetTaskCollection(): {
+			{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+			{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "tags", Value: 1}}},
+			{Keys: bson.D{{Key: "workspace_id", Value: 1}, {Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+			{Keys: bson.D{{Key: "parent_id", Value: 1}}},
+			{Keys: bson.D{{Key: "deleted_at", Value: 1}}, Options: options.Index().SetPartialFilterExpression(bson.M{"deleted_at": bson.M{"$exists": true}})},
+			{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "project_id", Value: 1}, {Key: "parent_id", Value: 1}, {Key: "rank", Value: 1}}},
+			{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "title", Value: "text"}}, Options: options.Index().SetName("title_text")},
+			{Keys: bson.D{{Key: "assignee_id", Value: 1}, {Key: "assignment_status", Value: 1}}, Options: options.Index().SetPartialFilterExpression(bson.M{"assignee_id": bson.M{"$exists": true}})},
+			{Keys: bson.D{{Key: "workspace_id", Value: 1}, {Key: "blocked_by", Value: 1}}, Options: options.Index().SetPartialFilterExpression(bson.M{"blocked_by": bson.M{"$exists": true}})},
+		},
+		GetReminderCollection(): {
+			{Keys: bson.D{{Key: "status", Value: 1}, {Key: "fire_at", Value: 1}}},
+			{Keys: bson.D{{Key: "task_id", Value: 1}, {Key: "offset_minutes", Value: 1}, {Key: "fire_at", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		GetTagCollection(): {
+			{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "name", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		GetBoardCollection(): {
+			{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "project_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		GetRevisionCollection(): {
+			{Keys: bson.D{{Key: "task_id", Value: 1}, {Key: "number", Value: -1}}, Options: options.Index().SetUnique(true)},
+		},
+		GetCommentCollection(): {
+			{Keys: bson.D{{Key: "task_id", Value: 1}, {Key: "_id", Value: 1}}},
+		},
+		GetShareCollection(): {
+			{Keys: bson.D{{Key: "resource", Value: 1}, {Key: "resource_id", Value: 1}, {Key: "user_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "workspace_id", Value: 1}, {Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		},
+		GetOperationCollection(): {
+			{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "undone", Value: 1}, {Key: "created_at", Value: -1}}},
+		},
+		GetSavedFilterCollection(): {
+			{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "name", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		GetProjectCollection(): {
+			{Keys: bson.D{{Key: "workspace_id", Value: 1}, {Key: "user_id", Value: 1}, {Key: "name", Value: 1}}},
+		},
+		GetWorkspaceCollection(): {
+			{Keys: bson.D{{Key: "owner_id", Value: 1}}, Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"personal": true})},
+		},
+		GetMembershipCollection(): {
+			{Keys: bson.D{{Key: "workspace_id", Value: 1}, {Key: "user_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		},
+	}
+
+	for collection, models := range indexes {
+		if _, err := collection.Indexes().CreateMany(ctx, models); err != nil {
+			return fmt.Errorf("failed to create indexes on %s: %w", collection.Name(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MigrateTaskStatuses - Rewrites legacy boolean task statuses into workflow states.
+// Tasks stored with status true become "done" (completed when last touched), false becomes "todo".
+func MigrateTaskStatuses(ctx context.Context) error {
+	taskCollection := GetTaskCollection()
+
+	done, err := taskCollection.UpdateMany(ctx, bson.M{"status": true}, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"status":       "done",
+			"completed_at": bson.M{"$max": bson.A{"$updated_at", "$created_at"}},
+		}}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to migrate completed tasks: %w", err)
+	}
+
+	todo, err := taskCollection.UpdateMany(ctx, bson.M{"status": false}, bson.M{"$set": bson.M{"status": "todo"}})
+	if err != nil {
+		return fmt.Errorf("failed to migrate open tasks: %w", err)
+	}
+
+	if done.ModifiedCount > 0 || todo.ModifiedCount > 0 {
+		log.Printf("Migrated %d legacy task statuses", done.ModifiedCount+todo.ModifiedCount)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package database
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// WithTransaction - Runs fn in a Mongo transaction, committing if it returns nil and aborting otherwise.
+// fn must do all of its work through the context it is given, and may be retried on transient errors.
+// Transactions need MongoDB to run as a replica set or sharded cluster.
+func WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := MongoClient.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessionCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessionCtx)
+	})
+	return err
+}
@@ -1,7 +1,9 @@
 package helper
 
 import (
+	"errors"
 	"log"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 
@@ -25,3 +27,29 @@ func RespondWithSuccess(c *gin.Context, code int, message string, data interface
 		Data:    data,
 	})
 }
+
+// RequestError - An error that carries the HTTP status and message it should be reported with
+type RequestError struct {
+	Code    int
+	Message string
+	Details string
+}
+
+func (e *RequestError) Error() string {
+	return e.Message + ": " + e.Details
+}
+
+// NewRequestError - Creates a RequestError for the given status code
+func NewRequestError(code int, message string, details string) *RequestError {
+	return &RequestError{Code: code, Message: message, Details: details}
+}
+
+// RespondWithRequestError - Responds with a RequestError's status, or a 500 for any other error
+func RespondWithRequestError(c *gin.Context, err error, fallbackMessage string) {
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) {
+		RespondWithError(c, reqErr.Code, reqErr.Message, reqErr.Details)
+		return
+	}
+	RespondWithError(c, http.StatusInternalServerError, fallbackMessage, err.Error())
+}
@@ -0,0 +1,147 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	database "task-manager/server/database"
+)
+
+// rankDigits - The alphabet ranks are written in. Its characters sort the same as their values,
+// so comparing two ranks as strings compares their positions.
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+const rankBase = len(rankDigits)
+
+// MaxRankLength - Ranks longer than this are due to be respread by the rebalance job
+const MaxRankLength = 16
+
+// RankBetween - Returns a rank that sorts strictly between before and after.
+// An empty before means the start of the list and an empty after its end.
+// Ranks never end in the lowest digit, so there is always room for another rank in front of any of them.
+func RankBetween(before string, after string) (string, error) {
+	if after != "" && before >= after {
+		return "", fmt.Errorf("rank %q must sort before %q", before, after)
+	}
+
+	var rank strings.Builder
+	bounded := after != ""
+	for i := 0; ; i++ {
+		low := 0
+		if i < len(before) {
+			low = strings.IndexByte(rankDigits, before[i])
+		}
+		high := rankBase
+		if bounded && i < len(after) {
+			high = strings.IndexByte(rankDigits, after[i])
+		} else if bounded {
+			// Only a rank ending in the lowest digit has nothing in front of it that starts the same
+			return "", fmt.Errorf("rank %q must not end in %q", after, rankDigits[0])
+		}
+		if low < 0 || high < 0 {
+			return "", fmt.Errorf("ranks may only contain %s", rankDigits)
+		}
+
+		if high-low > 1 {
+			rank.WriteByte(rankDigits[(low+high)/2])
+			return rank.String(), nil
+		}
+
+		rank.WriteByte(rankDigits[low])
+		// Once the prefix sorts below after, anything that follows it does too
+		if high != low {
+			bounded = false
+		}
+	}
+}
+
+// SpreadRanks - Returns n evenly spaced ranks in ascending order. They get one digit more than n needs,
+// so there is plenty of room between them.
+func SpreadRanks(n int) []string {
+	width, space := 1, rankBase
+	for space < rankBase*(n+1) {
+		width++
+		space *= rankBase
+	}
+
+	step := space / (n + 1)
+	ranks := make([]string, n)
+	for i := range ranks {
+		value := (i + 1) * step
+		digits := make([]byte, width)
+		for j := width - 1; j >= 0; j-- {
+			digits[j] = rankDigits[value%rankBase]
+			value /= rankBase
+		}
+		ranks[i] = strings.TrimRight(string(digits), rankDigits[:1])
+	}
+	return ranks
+}
+
+// SiblingFilter - Matches the tasks that are ordered together with a task: the user's tasks
+// with the same parent in the same project, leaving out the trash
+func SiblingFilter(userID string, projectID *primitive.ObjectID, parentID *primitive.ObjectID) bson.M {
+	filter := NotDeleted(bson.M{"user_id": userID, "project_id": nil, "parent_id": nil})
+	if projectID != nil {
+		filter["project_id"] = *projectID
+	}
+	if parentID != nil {
+		filter["parent_id"] = *parentID
+	}
+	return filter
+}
+
+// LastRank - Returns the highest rank among the tasks matched by filter, or "" when none are ranked
+func LastRank(ctx context.Context, filter bson.M) (string, error) {
+	var last struct {
+		Rank string `bson:"rank"`
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "rank", Value: -1}}).SetProjection(bson.M{"rank": 1})
+	err := database.GetTaskCollection().FindOne(ctx, filter, opts).Decode(&last)
+	if err == mongo.ErrNoDocuments {
+		return "", nil
+	}
+	return last.Rank, err
+}
+
+// RebalanceRanks - Gives every task matched by filter a fresh, short rank, keeping their current order.
+// Unranked tasks keep their place at the front, in creation order.
+// A task whose rank changed while this ran keeps its new rank.
+func RebalanceRanks(ctx context.Context, filter bson.M) error {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "rank", Value: 1}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetProjection(bson.M{"rank": 1})
+	cursor, err := database.GetTaskCollection().Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var tasks []struct {
+		ID   primitive.ObjectID `bson:"_id"`
+		Rank *string            `bson:"rank"`
+	}
+	if err := cursor.All(ctx, &tasks); err != nil {
+		return err
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ranks := SpreadRanks(len(tasks))
+	writes := make([]mongo.WriteModel, 0, len(tasks))
+	for i, task := range tasks {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": task.ID, "rank": task.Rank}).
+			SetUpdate(bson.M{"$set": bson.M{"rank": ranks[i]}}))
+	}
+
+	_, err = database.GetTaskCollection().BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
@@ -0,0 +1,141 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRankBetween(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   string
+	}{
+		{name: "empty list", before: "", after: "", want: "i"},
+		{name: "start of list", before: "", after: "i", want: "9"},
+		{name: "end of list", before: "i", after: "", want: "r"},
+		{name: "room between", before: "a", after: "c", want: "b"},
+		{name: "adjacent ranks", before: "a", after: "b", want: "ai"},
+		{name: "before the lowest digit", before: "", after: "1", want: "0i"},
+		{name: "after the highest digit", before: "z", after: "", want: "zi"},
+		{name: "after is longer", before: "a", after: "a1", want: "a0i"},
+		{name: "before is longer", before: "az", after: "b", want: "azi"},
+		{name: "shared prefix", before: "abc", after: "abe", want: "abd"},
+		{name: "max length ranks", before: strings.Repeat("z", MaxRankLength), after: "", want: strings.Repeat("z", MaxRankLength) + "i"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := RankBetween(test.before, test.after)
+			if err != nil {
+				t.Fatalf("RankBetween(%q, %q) returned error: %v", test.before, test.after, err)
+			}
+			if got != test.want {
+				t.Errorf("RankBetween(%q, %q) = %q, want %q", test.before, test.after, got, test.want)
+			}
+			if got <= test.before || (test.after != "" && got >= test.after) {
+				t.Errorf("RankBetween(%q, %q) = %q, which does not sort between them", test.before, test.after, got)
+			}
+		})
+	}
+}
+
+func TestRankBetweenErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+	}{
+		{name: "equal ranks", before: "a", after: "a"},
+		{name: "reversed ranks", before: "b", after: "a"},
+		{name: "invalid digit", before: "A", after: ""},
+		{name: "after ends in lowest digit", before: "a", after: "a0"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got, err := RankBetween(test.before, test.after); err == nil {
+				t.Errorf("RankBetween(%q, %q) = %q, want an error", test.before, test.after, got)
+			}
+		})
+	}
+}
+
+func TestRankBetweenRepeatedInserts(t *testing.T) {
+	tests := []struct {
+		name     string
+		position func(ranks []string) int
+	}{
+		{name: "always in front", position: func(ranks []string) int { return 0 }},
+		{name: "always at the end", position: func(ranks []string) int { return len(ranks) }},
+		{name: "always after the first", position: func(ranks []string) int { return min(1, len(ranks)) }},
+		{name: "always in the middle", position: func(ranks []string) int { return len(ranks) / 2 }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var ranks []string
+			for i := 0; i < 200; i++ {
+				at := test.position(ranks)
+				before, after := "", ""
+				if at > 0 {
+					before = ranks[at-1]
+				}
+				if at < len(ranks) {
+					after = ranks[at]
+				}
+				rank, err := RankBetween(before, after)
+				if err != nil {
+					t.Fatalf("insert %d returned error: %v", i, err)
+				}
+				if strings.HasSuffix(rank, rankDigits[:1]) {
+					t.Fatalf("insert %d: %q ends in the lowest digit", i, rank)
+				}
+				ranks = append(ranks[:at], append([]string{rank}, ranks[at:]...)...)
+			}
+			for i := 1; i < len(ranks); i++ {
+				if ranks[i] <= ranks[i-1] {
+					t.Fatalf("%q does not sort after %q", ranks[i], ranks[i-1])
+				}
+			}
+		})
+	}
+}
+
+func TestSpreadRanks(t *testing.T) {
+	tests := []struct {
+		n         int
+		maxLength int
+	}{
+		{n: 0, maxLength: 0},
+		{n: 1, maxLength: 2},
+		{n: 35, maxLength: 2},
+		{n: 36, maxLength: 3},
+		{n: 1295, maxLength: 3},
+		{n: 1296, maxLength: 4},
+	}
+	for _, test := range tests {
+		ranks := SpreadRanks(test.n)
+		if len(ranks) != test.n {
+			t.Fatalf("SpreadRanks(%d) returned %d ranks", test.n, len(ranks))
+		}
+		for i, rank := range ranks {
+			if rank == "" || len(rank) > test.maxLength {
+				t.Errorf("SpreadRanks(%d)[%d] = %q, want 1 to %d digits", test.n, i, rank, test.maxLength)
+			}
+			if strings.HasSuffix(rank, rankDigits[:1]) {
+				t.Errorf("SpreadRanks(%d)[%d] = %q ends in the lowest digit", test.n, i, rank)
+			}
+			if i > 0 && rank <= ranks[i-1] {
+				t.Errorf("SpreadRanks(%d)[%d] = %q does not sort after %q", test.n, i, rank, ranks[i-1])
+			}
+		}
+		// Spread ranks leave room for new tasks at either end and between any two of them
+		if test.n > 0 {
+			if _, err := RankBetween("", ranks[0]); err != nil {
+				t.Errorf("SpreadRanks(%d) leaves no room in front: %v", test.n, err)
+			}
+			if _, err := RankBetween(ranks[len(ranks)-1], ""); err != nil {
+				t.Errorf("SpreadRanks(%d) leaves no room at the end: %v", test.n, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+
+	model "task-manager/server/models"
+)
+
+// recurrenceOptions - Parses the task's RRULE in its timezone, rejecting embedded DTSTARTs
+// because occurrences are always anchored on the task's own dates
+func recurrenceOptions(recurrence model.Recurrence) (*rrule.ROption, *time.Location, error) {
+	location, err := time.LoadLocation(recurrence.Timezone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unknown timezone %q", recurrence.Timezone)
+	}
+
+	rule := strings.TrimSpace(recurrence.RRule)
+	if strings.Contains(rule, "\n") || strings.Contains(rule, "DTSTART") {
+		return nil, nil, errors.New("rrule must be a single RRULE without DTSTART")
+	}
+
+	options, err := rrule.StrToROptionInLocation(rule, location)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid rrule: %w", err)
+	}
+	return options, location, nil
+}
+
+// ValidateRecurrence - Checks that the task's RRULE and timezone can be evaluated
+func ValidateRecurrence(recurrence model.Recurrence) error {
+	options, location, err := recurrenceOptions(recurrence)
+	if err != nil {
+		return err
+	}
+
+	options.Dtstart = time.Now().In(location)
+	if _, err := rrule.NewRRule(*options); err != nil {
+		return fmt.Errorf("invalid rrule: %w", err)
+	}
+	return nil
+}
+
+// NextOccurrences - Computes up to n due dates following the current occurrence.
+// In "due" mode the rule runs from the series start, so dates stay on schedule no matter when tasks are finished.
+// In "completion" mode each occurrence is counted from when the previous one was completed,
+// assumed to be on its due date for occurrences that have not happened yet.
+func NextOccurrences(recurrence model.Recurrence, due time.Time, completedAt time.Time, n int) ([]time.Time, error) {
+	options, location, err := recurrenceOptions(recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	if recurrence.Mode == model.RepeatFromCompletion {
+		// COUNT limits the series as a whole, so it is tracked through the occurrence number instead
+		remaining := n
+		if options.Count > 0 {
+			remaining = min(n, options.Count-recurrence.Occurrence)
+		}
+		options.Count = 0
+
+		occurrences := []time.Time{}
+		from := completedAt
+		for len(occurrences) < remaining {
+			options.Dtstart = from.In(location)
+			rule, err := rrule.NewRRule(*options)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rrule: %w", err)
+			}
+
+			next := rule.After(from, false)
+			if next.IsZero() {
+				break
+			}
+			occurrences = append(occurrences, next.UTC())
+			from = next
+		}
+		return occurrences, nil
+	}
+
+	// COUNT is tracked through the occurrence number here too, as the rule no longer runs from the series start
+	remaining := n
+	if options.Count > 0 {
+		remaining = min(n, options.Count-recurrence.Occurrence)
+	}
+	options.Count = 0
+
+	options.Dtstart = anchorBefore(options, recurrence.SeriesStart.In(location), due.In(location))
+	rule, err := rrule.NewRRule(*options)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rrule: %w", err)
+	}
+
+	occurrences := []time.Time{}
+	iterator := rule.Iterator()
+	for next, ok := iterator(); ok && len(occurrences) < remaining; next, ok = iterator() {
+		if next.After(due) {
+			occurrences = append(occurrences, next.UTC())
+		}
+	}
+	return occurrences, nil
+}
+
+// ruleWeekdays - The rrule weekday for each time.Weekday
+var ruleWeekdays = []rrule.Weekday{rrule.SU, rrule.MO, rrule.TU, rrule.WE, rrule.TH, rrule.FR, rrule.SA}
+
+// anchorBefore - Returns a start for the rule, a whole number of intervals after the series start and no later
+// than due, so evaluating the rule does not walk through every past occurrence of the series.
+// The parts of the schedule the rule takes from its start, like the weekday of a weekly rule,
+// are pinned to the series start first so that the schedule stays the same.
+func anchorBefore(options *rrule.ROption, start time.Time, due time.Time) time.Time {
+	if !due.After(start) {
+		return start
+	}
+
+	if len(options.Byweekno) == 0 && len(options.Byyearday) == 0 && len(options.Bymonthday) == 0 && len(options.Byweekday) == 0 && len(options.Byeaster) == 0 {
+		switch options.Freq {
+		case rrule.YEARLY:
+			if len(options.Bymonth) == 0 {
+				options.Bymonth = []int{int(start.Month())}
+			}
+			options.Bymonthday = []int{start.Day()}
+		case rrule.MONTHLY:
+			options.Bymonthday = []int{start.Day()}
+		case rrule.WEEKLY:
+			options.Byweekday = []rrule.Weekday{ruleWeekdays[start.Weekday()]}
+		}
+	}
+	if len(options.Byhour) == 0 && options.Freq < rrule.HOURLY {
+		options.Byhour = []int{start.Hour()}
+	}
+	if len(options.Byminute) == 0 && options.Freq < rrule.MINUTELY {
+		options.Byminute = []int{start.Minute()}
+	}
+	if len(options.Bysecond) == 0 && options.Freq < rrule.SECONDLY {
+		options.Bysecond = []int{start.Second()}
+	}
+
+	// Rules step through wall-clock time, so that is what the intervals are counted in
+	wallClock := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	}
+	interval := max(options.Interval, 1)
+	location := start.Location()
+	days := int(wallClock(due).Truncate(24*time.Hour).Sub(wallClock(start).Truncate(24*time.Hour)).Hours() / 24)
+
+	// Yearly and monthly rules move to the first day of a later period, as months differ in length
+	var anchor time.Time
+	switch options.Freq {
+	case rrule.YEARLY:
+		years := (due.Year() - start.Year()) / interval * interval
+		anchor = time.Date(start.Year()+years, time.January, 1, 0, 0, 0, 0, location)
+	case rrule.MONTHLY:
+		months := ((due.Year()-start.Year())*12 + int(due.Month()-start.Month())) / interval * interval
+		anchor = time.Date(start.Year(), start.Month()+time.Month(months), 1, 0, 0, 0, 0, location)
+	case rrule.WEEKLY, rrule.DAILY:
+		step := interval
+		if options.Freq == rrule.WEEKLY {
+			step *= 7
+		}
+		anchor = start.AddDate(0, 0, days/step*step)
+		// On due's own day the start's time of day may still be ahead of it
+		if anchor.After(due) {
+			anchor = anchor.AddDate(0, 0, -step)
+		}
+	default:
+		step := map[rrule.Frequency]int{rrule.HOURLY: 3600, rrule.MINUTELY: 60, rrule.SECONDLY: 1}[options.Freq] * interval
+		seconds := int(wallClock(due).Sub(wallClock(start))/time.Second) / step * step
+		anchor = time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), start.Minute(), start.Second()+seconds, 0, location)
+		// Around a daylight saving change the wall clock can put it just past due
+		if anchor.After(due) {
+			anchor = time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), start.Minute(), start.Second()+seconds-step, 0, location)
+		}
+	}
+
+	// The anchor stays in the series start's period, or one after it, and never passes due
+	if !anchor.After(start) || anchor.After(due) {
+		return start
+	}
+	return anchor
+}
@@ -0,0 +1,202 @@
+package helper
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/teambition/rrule-go"
+
+	model "task-manager/server/models"
+)
+
+func TestNextOccurrences(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("loading timezone: %v", err)
+	}
+	utc := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	local := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, newYork).UTC()
+	}
+
+	tests := []struct {
+		name        string
+		recurrence  model.Recurrence
+		due         time.Time
+		completedAt time.Time
+		n           int
+		want        []time.Time
+	}{
+		{
+			name:       "daily",
+			recurrence: model.Recurrence{RRule: "FREQ=DAILY", Timezone: "UTC", Mode: model.RepeatFromDue, SeriesStart: utc(2026, 10, 1, 9, 0), Occurrence: 1},
+			due:        utc(2026, 10, 1, 9, 0),
+			n:          3,
+			want:       []time.Time{utc(2026, 10, 2, 9, 0), utc(2026, 10, 3, 9, 0), utc(2026, 10, 4, 9, 0)},
+		},
+		{
+			name:        "due mode keeps to the schedule when completed late",
+			recurrence:  model.Recurrence{RRule: "FREQ=WEEKLY", Timezone: "UTC", Mode: model.RepeatFromDue, SeriesStart: utc(2026, 10, 5, 9, 0), Occurrence: 1},
+			due:         utc(2026, 10, 5, 9, 0),
+			completedAt: utc(2026, 10, 9, 17, 0),
+			n:           2,
+			want:        []time.Time{utc(2026, 10, 12, 9, 0), utc(2026, 10, 19, 9, 0)},
+		},
+		{
+			name:        "completion mode counts from the completion",
+			recurrence:  model.Recurrence{RRule: "FREQ=DAILY;INTERVAL=2", Timezone: "UTC", Mode: model.RepeatFromCompletion, SeriesStart: utc(2026, 10, 1, 9, 0), Occurrence: 1},
+			due:         utc(2026, 10, 1, 9, 0),
+			completedAt: utc(2026, 10, 4, 17, 30),
+			n:           2,
+			want:        []time.Time{utc(2026, 10, 6, 17, 30), utc(2026, 10, 8, 17, 30)},
+		},
+		{
+			name:       "count leaves the occurrences not used yet",
+			recurrence: model.Recurrence{RRule: "FREQ=DAILY;COUNT=4", Timezone: "UTC", Mode: model.RepeatFromDue, SeriesStart: utc(2026, 10, 1, 9, 0), Occurrence: 2},
+			due:        utc(2026, 10, 2, 9, 0),
+			n:          5,
+			want:       []time.Time{utc(2026, 10, 3, 9, 0), utc(2026, 10, 4, 9, 0)},
+		},
+		{
+			name:       "count reached",
+			recurrence: model.Recurrence{RRule: "FREQ=DAILY;COUNT=4", Timezone: "UTC", Mode: model.RepeatFromDue, SeriesStart: utc(2026, 10, 1, 9, 0), Occurrence: 4},
+			due:        utc(2026, 10, 4, 9, 0),
+			n:          1,
+			want:       []time.Time{},
+		},
+		{
+			name:        "count in completion mode",
+			recurrence:  model.Recurrence{RRule: "FREQ=DAILY;COUNT=3", Timezone: "UTC", Mode: model.RepeatFromCompletion, SeriesStart: utc(2026, 10, 1, 9, 0), Occurrence: 2},
+			due:         utc(2026, 10, 2, 9, 0),
+			completedAt: utc(2026, 10, 2, 12, 0),
+			n:           3,
+			want:        []time.Time{utc(2026, 10, 3, 12, 0)},
+		},
+		{
+			name:       "until ends the series",
+			recurrence: model.Recurrence{RRule: "FREQ=DAILY;UNTIL=20261004T090000Z", Timezone: "UTC", Mode: model.RepeatFromDue, SeriesStart: utc(2026, 10, 1, 9, 0), Occurrence: 1},
+			due:        utc(2026, 10, 2, 9, 0),
+			n:          5,
+			want:       []time.Time{utc(2026, 10, 3, 9, 0), utc(2026, 10, 4, 9, 0)},
+		},
+		{
+			name:       "until already passed",
+			recurrence: model.Recurrence{RRule: "FREQ=WEEKLY;UNTIL=20261010T000000Z", Timezone: "UTC", Mode: model.RepeatFromDue, SeriesStart: utc(2026, 10, 1, 9, 0), Occurrence: 2},
+			due:        utc(2026, 10, 8, 9, 0),
+			n:          1,
+			want:       []time.Time{},
+		},
+		{
+			name:       "last day of the month",
+			recurrence: model.Recurrence{RRule: "FREQ=MONTHLY;BYMONTHDAY=-1", Timezone: "UTC", Mode: model.RepeatFromDue, SeriesStart: utc(2026, 1, 31, 9, 0), Occurrence: 1},
+			due:        utc(2026, 1, 31, 9, 0),
+			n:          4,
+			want:       []time.Time{utc(2026, 2, 28, 9, 0), utc(2026, 3, 31, 9, 0), utc(2026, 4, 30, 9, 0), utc(2026, 5, 31, 9, 0)},
+		},
+		{
+			name:       "monthly on the 31st skips shorter months",
+			recurrence: model.Recurrence{RRule: "FREQ=MONTHLY", Timezone: "UTC", Mode: model.RepeatFromDue, SeriesStart: utc(2026, 1, 31, 9, 0), Occurrence: 1},
+			due:        utc(2026, 1, 31, 9, 0),
+			n:          3,
+			want:       []time.Time{utc(2026, 3, 31, 9, 0), utc(2026, 5, 31, 9, 0), utc(2026, 7, 31, 9, 0)},
+		},
+		{
+			name:       "monthly on the 31st years into the series",
+			recurrence: model.Recurrence{RRule: "FREQ=MONTHLY", Timezone: "UTC", Mode: model.RepeatFromDue, SeriesStart: utc(2020, 1, 31, 9, 0), Occurrence: 40},
+			due:        utc(2026, 7, 31, 9, 0),
+			n:          2,
+			want:       []time.Time{utc(2026, 8, 31, 9, 0), utc(2026, 10, 31, 9, 0)},
+		},
+		{
+			name:       "leap day yearly",
+			recurrence: model.Recurrence{RRule: "FREQ=YEARLY", Timezone: "UTC", Mode: model.RepeatFromDue, SeriesStart: utc(2020, 2, 29, 9, 0), Occurrence: 1},
+			due:        utc(2020, 2, 29, 9, 0),
+			n:          2,
+			want:       []time.Time{utc(2024, 2, 29, 9, 0), utc(2028, 2, 29, 9, 0)},
+		},
+		{
+			name:       "daily keeps the local time across the spring change",
+			recurrence: model.Recurrence{RRule: "FREQ=DAILY", Timezone: "America/New_York", Mode: model.RepeatFromDue, SeriesStart: local(2026, 3, 6, 9, 0), Occurrence: 1},
+			due:        local(2026, 3, 6, 9, 0),
+			n:          3,
+			want:       []time.Time{utc(2026, 3, 7, 14, 0), utc(2026, 3, 8, 13, 0), utc(2026, 3, 9, 13, 0)},
+		},
+		{
+			name:       "weekly keeps the local time across the autumn change, years into the series",
+			recurrence: model.Recurrence{RRule: "FREQ=WEEKLY", Timezone: "America/New_York", Mode: model.RepeatFromDue, SeriesStart: local(2021, 6, 2, 8, 30), Occurrence: 284},
+			due:        local(2026, 10, 28, 8, 30),
+			n:          2,
+			want:       []time.Time{utc(2026, 11, 4, 13, 30), utc(2026, 11, 11, 13, 30)},
+		},
+		{
+			name:       "weekday rule in a timezone",
+			recurrence: model.Recurrence{RRule: "FREQ=WEEKLY;BYDAY=MO,FR", Timezone: "America/New_York", Mode: model.RepeatFromDue, SeriesStart: local(2026, 10, 30, 18, 0), Occurrence: 1},
+			due:        local(2026, 10, 30, 18, 0),
+			n:          3,
+			want:       []time.Time{utc(2026, 11, 2, 23, 0), utc(2026, 11, 6, 23, 0), utc(2026, 11, 9, 23, 0)},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := NextOccurrences(test.recurrence, test.due, test.completedAt, test.n)
+			if err != nil {
+				t.Fatalf("NextOccurrences returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("NextOccurrences =\n%v\nwant\n%v", got, test.want)
+			}
+		})
+	}
+}
+
+// TestNextOccurrencesMatchesFullSeries checks that starting the rule near due gives the same dates as running it
+// from the series start, which is what anchorBefore must preserve
+func TestNextOccurrencesMatchesFullSeries(t *testing.T) {
+	tests := []struct {
+		rule     string
+		timezone string
+		start    time.Time
+	}{
+		{rule: "FREQ=DAILY;INTERVAL=3", timezone: "UTC", start: time.Date(2019, 5, 17, 7, 45, 0, 0, time.UTC)},
+		{rule: "FREQ=WEEKLY;INTERVAL=2", timezone: "Europe/Berlin", start: time.Date(2019, 3, 30, 23, 30, 0, 0, time.UTC)},
+		{rule: "FREQ=MONTHLY;INTERVAL=5", timezone: "UTC", start: time.Date(2019, 8, 31, 12, 0, 0, 0, time.UTC)},
+		{rule: "FREQ=MONTHLY;BYDAY=-1FR", timezone: "America/New_York", start: time.Date(2019, 1, 25, 14, 0, 0, 0, time.UTC)},
+		{rule: "FREQ=YEARLY;INTERVAL=2", timezone: "Australia/Sydney", start: time.Date(2019, 10, 6, 1, 0, 0, 0, time.UTC)},
+		{rule: "FREQ=HOURLY;INTERVAL=7", timezone: "America/New_York", start: time.Date(2019, 11, 2, 4, 0, 0, 0, time.UTC)},
+		{rule: "FREQ=MINUTELY;INTERVAL=45", timezone: "UTC", start: time.Date(2026, 10, 1, 0, 10, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		t.Run(test.rule+" "+test.timezone, func(t *testing.T) {
+			location, err := time.LoadLocation(test.timezone)
+			if err != nil {
+				t.Fatalf("loading timezone: %v", err)
+			}
+			options, err := rrule.StrToROptionInLocation(test.rule, location)
+			if err != nil {
+				t.Fatalf("parsing rule: %v", err)
+			}
+			options.Dtstart = test.start.In(location)
+			options.Count = 60
+			full, err := rrule.NewRRule(*options)
+			if err != nil {
+				t.Fatalf("building rule: %v", err)
+			}
+			series := full.All()
+
+			recurrence := model.Recurrence{RRule: test.rule, Timezone: test.timezone, Mode: model.RepeatFromDue, SeriesStart: test.start}
+			for i := 0; i+3 < len(series); i += 7 {
+				got, err := NextOccurrences(recurrence, series[i].UTC(), time.Time{}, 3)
+				if err != nil {
+					t.Fatalf("NextOccurrences returned error: %v", err)
+				}
+				want := []time.Time{series[i+1].UTC(), series[i+2].UTC(), series[i+3].UTC()}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("after occurrence %d (%v): got %v, want %v", i+1, series[i].UTC(), got, want)
+				}
+			}
+		})
+	}
+}
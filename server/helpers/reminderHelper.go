@@ -0,0 +1,61 @@
+package helper
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	database "task-manager/server/database"
+	model "task-manager/server/models"
+)
+
+// ScheduleReminders - Replaces the task's queued reminders with one per offset (in minutes) before its due time.
+// Reminders that would already be in the past are not queued, and ones already sent are never queued again.
+func ScheduleReminders(ctx context.Context, task model.Task) error {
+	if err := CancelReminders(ctx, []primitive.ObjectID{task.ID}); err != nil {
+		return err
+	}
+	if task.DueAt == nil || task.Status.IsClosed() || len(task.ReminderOffsets) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	var reminders []interface{}
+	for _, offset := range task.ReminderOffsets {
+		fireAt := task.DueAt.Add(-time.Duration(offset) * time.Minute)
+		if fireAt.Before(now) {
+			continue
+		}
+		reminders = append(reminders, model.Reminder{
+			ID:            primitive.NewObjectID(),
+			TaskID:        task.ID,
+			UserID:        task.UserID,
+			OffsetMinutes: offset,
+			FireAt:        fireAt,
+			Status:        model.ReminderPending,
+			Created:       now,
+		})
+	}
+	if len(reminders) == 0 {
+		return nil
+	}
+
+	_, err := database.GetReminderCollection().InsertMany(ctx, reminders, options.InsertMany().SetOrdered(false))
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+// CancelReminders - Drops the queued reminders of the given tasks that have not been sent yet
+func CancelReminders(ctx context.Context, taskIDs []primitive.ObjectID) error {
+	_, err := database.GetReminderCollection().DeleteMany(ctx, bson.M{
+		"task_id": bson.M{"$in": taskIDs},
+		"status":  model.ReminderPending,
+	})
+	return err
+}
@@ -25,7 +25,13 @@ func HashKey() string {
 	return secretKey
 }
 
-var hashKey = HashKey()
+var hashKey string
+
+// LoadHashKey - Reads the key tokens are signed with, exiting when it is not set.
+// The server calls it once at startup, after the .env file is loaded.
+func LoadHashKey() {
+	hashKey = HashKey()
+}
 
 const (
 	AccessTokenExpiry  = 24
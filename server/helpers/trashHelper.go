@@ -0,0 +1,83 @@
+package helper
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	database "task-manager/server/database"
+	model "task-manager/server/models"
+	storage "task-manager/server/storage"
+)
+
+// purgeBatchSize - How many tasks PurgeTasks removes per round trip
+const purgeBatchSize = 500
+
+// NotDeleted - Restricts a task filter to tasks that are not in the trash.
+// Every read of tasks outside the trash endpoints goes through it.
+func NotDeleted(filter bson.M) bson.M {
+	filter["deleted_at"] = nil
+	return filter
+}
+
+// PurgeTasks - Permanently removes the trashed tasks matched by filter, along with their reminders,
+// history, comments, attachments, shares and the dependencies on them. Tasks that are not in the trash are never matched.
+// Returns how many tasks were removed.
+func PurgeTasks(ctx context.Context, filter bson.M) (int64, error) {
+	filter = bson.M{"$and": bson.A{filter, bson.M{"deleted_at": bson.M{"$ne": nil}}}}
+	collection := database.GetTaskCollection()
+
+	var purged int64
+	for {
+		opts := options.Find().SetProjection(bson.M{"_id": 1, "attachments._id": 1}).SetLimit(purgeBatchSize)
+		cursor, err := collection.Find(ctx, filter, opts)
+		if err != nil {
+			return purged, err
+		}
+		var tasks []struct {
+			ID          primitive.ObjectID `bson:"_id"`
+			Attachments []struct {
+				ID primitive.ObjectID `bson:"_id"`
+			} `bson:"attachments"`
+		}
+		err = cursor.All(ctx, &tasks)
+		if err != nil || len(tasks) == 0 {
+			return purged, err
+		}
+
+		ids := make([]primitive.ObjectID, len(tasks))
+		for i, task := range tasks {
+			ids[i] = task.ID
+			for _, attachment := range task.Attachments {
+				if err := storage.Attachments.Delete(ctx, attachment.ID.Hex()); err != nil {
+					return purged, err
+				}
+			}
+		}
+		if _, err := database.GetReminderCollection().DeleteMany(ctx, bson.M{"task_id": bson.M{"$in": ids}}); err != nil {
+			return purged, err
+		}
+		if _, err := database.GetRevisionCollection().DeleteMany(ctx, bson.M{"task_id": bson.M{"$in": ids}}); err != nil {
+			return purged, err
+		}
+		if _, err := database.GetCommentCollection().DeleteMany(ctx, bson.M{"task_id": bson.M{"$in": ids}}); err != nil {
+			return purged, err
+		}
+		if _, err := database.GetShareCollection().DeleteMany(ctx, bson.M{"resource": model.ShareTask, "resource_id": bson.M{"$in": ids}}); err != nil {
+			return purged, err
+		}
+		if _, err := collection.UpdateMany(ctx, bson.M{"blocked_by": bson.M{"$in": ids}}, bson.M{"$pull": bson.M{"blocked_by": bson.M{"$in": ids}}}); err != nil {
+			return purged, err
+		}
+		result, err := collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}, "deleted_at": bson.M{"$ne": nil}})
+		if err != nil {
+			return purged, err
+		}
+		purged += result.DeletedCount
+		if len(tasks) < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
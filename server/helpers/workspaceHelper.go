@@ -0,0 +1,207 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	database "task-manager/server/database"
+	model "task-manager/server/models"
+)
+
+// workspaceKey - The context key the request's workspace is kept under
+type workspaceKey struct{}
+
+// workspaceScope - The workspace a request works in and the user's role there
+type workspaceScope struct {
+	id   primitive.ObjectID
+	role model.WorkspaceRole
+}
+
+// WithWorkspace - Returns a copy of ctx that scopes task and project queries to the workspace
+func WithWorkspace(ctx context.Context, id primitive.ObjectID, role model.WorkspaceRole) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, workspaceScope{id: id, role: role})
+}
+
+// WorkspaceFromContext - Returns the workspace of the request and the user's role in it
+func WorkspaceFromContext(ctx context.Context) (primitive.ObjectID, model.WorkspaceRole, bool) {
+	scope, ok := ctx.Value(workspaceKey{}).(workspaceScope)
+	return scope.id, scope.role, ok
+}
+
+// GetWorkspace - Helper function to extract the workspace the workspace middleware resolved
+func GetWorkspace(c *gin.Context) (primitive.ObjectID, model.WorkspaceRole, bool) {
+	return WorkspaceFromContext(c.Request.Context())
+}
+
+// InWorkspace - Restricts a task or project filter to the workspace of the request.
+// Every task and project query made for a request goes through it. Without a workspace in ctx
+// nothing matches, so a query that bypasses the middleware fails closed instead of leaking.
+func InWorkspace(ctx context.Context, filter bson.M) bson.M {
+	id, _, _ := WorkspaceFromContext(ctx)
+	filter["workspace_id"] = id
+	return filter
+}
+
+// FindMembership - Loads the user's membership of a workspace
+func FindMembership(ctx context.Context, workspaceID primitive.ObjectID, userID string) (model.Membership, error) {
+	var membership model.Membership
+	err := database.GetMembershipCollection().FindOne(ctx, bson.M{"workspace_id": workspaceID, "user_id": userID}).Decode(&membership)
+	if err == mongo.ErrNoDocuments {
+		return membership, NewRequestError(http.StatusNotFound, "Workspace not found", "No workspace found for the specified ID and user")
+	}
+	return membership, err
+}
+
+// IsPersonalWorkspace - Reports whether the workspace is someone's personal one, which nobody else can join
+func IsPersonalWorkspace(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	count, err := database.GetWorkspaceCollection().CountDocuments(ctx, bson.M{"_id": id, "personal": true})
+	return count > 0, err
+}
+
+// sharedWithFilter - Matches the shares the user received and the tasks handed to them
+func sharedWithFilter(userID string) (bson.M, bson.M) {
+	shares := bson.M{"user_id": userID}
+	assigned := NotDeleted(bson.M{"assignee_id": userID, "assignment_status": bson.M{"$in": model.ActiveAssignments}})
+	return shares, assigned
+}
+
+// FindWorkspaceRole - Returns the user's role in a workspace. Since nobody can join a personal workspace, users
+// its owner shared or assigned something with reach it as guests, which gives them access to those alone.
+func FindWorkspaceRole(ctx context.Context, id primitive.ObjectID, userID string) (model.WorkspaceRole, error) {
+	membership, err := FindMembership(ctx, id, userID)
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) {
+		return membership.Role, err
+	}
+
+	personal, findErr := IsPersonalWorkspace(ctx, id)
+	if findErr != nil {
+		return "", findErr
+	}
+	if !personal {
+		return "", err
+	}
+	shares, assigned := sharedWithFilter(userID)
+	shares["workspace_id"], assigned["workspace_id"] = id, id
+	limit := options.Count().SetLimit(1)
+	count, findErr := database.GetShareCollection().CountDocuments(ctx, shares, limit)
+	if findErr == nil && count == 0 {
+		count, findErr = database.GetTaskCollection().CountDocuments(ctx, assigned, limit)
+	}
+	if findErr != nil {
+		return "", findErr
+	}
+	if count == 0 {
+		return "", err
+	}
+	return model.WorkspaceGuest, nil
+}
+
+// GuestWorkspaceIDs - Lists the personal workspaces of other users that the user reaches as a guest
+func GuestWorkspaceIDs(ctx context.Context, userID string) ([]primitive.ObjectID, error) {
+	shares, assigned := sharedWithFilter(userID)
+	fromShares, err := database.GetShareCollection().Distinct(ctx, "workspace_id", shares)
+	if err != nil {
+		return nil, err
+	}
+	fromTasks, err := database.GetTaskCollection().Distinct(ctx, "workspace_id", assigned)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []primitive.ObjectID
+	for _, value := range append(fromShares, fromTasks...) {
+		if id, ok := value.(primitive.ObjectID); ok {
+			candidates = append(candidates, id)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	filter := bson.M{"_id": bson.M{"$in": candidates}, "personal": true, "owner_id": bson.M{"$ne": userID}}
+	ids, err := database.GetWorkspaceCollection().Distinct(ctx, "_id", filter)
+	if err != nil {
+		return nil, err
+	}
+	workspaceIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, value := range ids {
+		if id, ok := value.(primitive.ObjectID); ok {
+			workspaceIDs = append(workspaceIDs, id)
+		}
+	}
+	return workspaceIDs, nil
+}
+
+// EnsurePersonalWorkspace - Returns the user's personal workspace, creating it the first time.
+// Tasks, projects, shares and undo entries from before workspaces existed are moved into it once, the first time it is used.
+func EnsurePersonalWorkspace(ctx context.Context, userID string, username string) (model.Workspace, error) {
+	collection := database.GetWorkspaceCollection()
+	filter := bson.M{"owner_id": userID, "personal": true}
+
+	var workspace model.Workspace
+	err := collection.FindOne(ctx, filter).Decode(&workspace)
+	if err == mongo.ErrNoDocuments {
+		now := time.Now().UTC()
+		workspace = model.Workspace{
+			ID:       primitive.NewObjectID(),
+			Name:     username + "'s workspace",
+			Personal: true,
+			OwnerID:  userID,
+			Created:  now,
+			Updated:  now,
+		}
+		// The unique index on personal workspaces lets only one of several concurrent requests create it
+		if _, err = collection.InsertOne(ctx, workspace); mongo.IsDuplicateKeyError(err) {
+			err = collection.FindOne(ctx, filter).Decode(&workspace)
+		}
+	}
+	if err != nil {
+		return workspace, fmt.Errorf("fetching personal workspace: %w", err)
+	}
+	if workspace.Migrated {
+		return workspace, nil
+	}
+
+	now := time.Now().UTC()
+	_, err = database.GetMembershipCollection().UpdateOne(ctx,
+		bson.M{"workspace_id": workspace.ID, "user_id": userID},
+		bson.M{
+			"$set":         bson.M{"username": username, "role": model.WorkspaceOwner, "updated_at": now},
+			"$setOnInsert": bson.M{"created_at": now},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return workspace, fmt.Errorf("adding workspace owner: %w", err)
+	}
+
+	legacy := bson.M{"user_id": userID, "workspace_id": bson.M{"$exists": false}}
+	move := bson.M{"$set": bson.M{"workspace_id": workspace.ID}}
+	if _, err := database.GetTaskCollection().UpdateMany(ctx, legacy, move); err != nil {
+		return workspace, fmt.Errorf("moving tasks into workspace: %w", err)
+	}
+	if _, err := database.GetProjectCollection().UpdateMany(ctx, legacy, move); err != nil {
+		return workspace, fmt.Errorf("moving projects into workspace: %w", err)
+	}
+	if _, err := database.GetOperationCollection().UpdateMany(ctx, legacy, move); err != nil {
+		return workspace, fmt.Errorf("moving undo log into workspace: %w", err)
+	}
+	if _, err := database.GetShareCollection().UpdateMany(ctx, bson.M{"owner_id": userID, "workspace_id": bson.M{"$exists": false}}, move); err != nil {
+		return workspace, fmt.Errorf("moving shares into workspace: %w", err)
+	}
+	if _, err := collection.UpdateOne(ctx, bson.M{"_id": workspace.ID}, bson.M{"$set": bson.M{"migrated": true}}); err != nil {
+		return workspace, fmt.Errorf("marking workspace migrated: %w", err)
+	}
+	workspace.Migrated = true
+	return workspace, nil
+}
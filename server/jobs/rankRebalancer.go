@@ -0,0 +1,91 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	database "task-manager/server/database"
+	helper "task-manager/server/helpers"
+)
+
+// maxRebalancesPerRun - How many sibling lists one pass respreads, so a backlog is worked off gradually
+const maxRebalancesPerRun = 100
+
+// StartRankRebalancer - Every interval, respreads the ranks of sibling lists whose ranks have grown past
+// helper.MaxRankLength from repeated moves into the same gap. Runs until ctx is cancelled; wg is released once it has stopped.
+func StartRankRebalancer(ctx context.Context, wg *sync.WaitGroup, interval time.Duration) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		log.Printf("Rank rebalancer started, running every %s", interval)
+		for {
+			select {
+			case <-ctx.Done():
+				log.Println("Rank rebalancer stopped.")
+				return
+			case <-ticker.C:
+				rebalanceLongRanks(ctx)
+			}
+		}
+	}()
+}
+
+// rebalanceLongRanks - Finds the sibling lists holding an overlong rank and rebalances each of them
+func rebalanceLongRanks(ctx context.Context) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"rank":       bson.M{"$type": "string"},
+			"deleted_at": nil,
+			"$expr":      bson.M{"$gt": bson.A{bson.M{"$strLenCP": "$rank"}, helper.MaxRankLength}},
+		}}},
+		{{Key: "$group", Value: bson.M{"_id": bson.M{
+			"workspace_id": "$workspace_id",
+			"user_id":      "$user_id",
+			"project_id":   "$project_id",
+			"parent_id":    "$parent_id",
+		}}}},
+		{{Key: "$limit", Value: maxRebalancesPerRun}},
+	}
+
+	cursor, err := database.GetTaskCollection().Aggregate(ctx, pipeline)
+	if err != nil {
+		log.Printf("Error finding tasks to rebalance: %v", err)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var groups []struct {
+		ID struct {
+			WorkspaceID *primitive.ObjectID `bson:"workspace_id"`
+			UserID      string              `bson:"user_id"`
+			ProjectID   *primitive.ObjectID `bson:"project_id"`
+			ParentID    *primitive.ObjectID `bson:"parent_id"`
+		} `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		log.Printf("Error decoding tasks to rebalance: %v", err)
+		return
+	}
+
+	for _, group := range groups {
+		if ctx.Err() != nil {
+			return
+		}
+		// Sibling lists are per workspace; tasks not yet moved into one have no workspace_id and match nil
+		filter := helper.SiblingFilter(group.ID.UserID, group.ID.ProjectID, group.ID.ParentID)
+		filter["workspace_id"] = group.ID.WorkspaceID
+		if err := helper.RebalanceRanks(ctx, filter); err != nil {
+			log.Printf("Error rebalancing ranks for user %s: %v", group.ID.UserID, err)
+		}
+	}
+}
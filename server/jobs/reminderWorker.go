@@ -0,0 +1,161 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	database "task-manager/server/database"
+	helper "task-manager/server/helpers"
+	model "task-manager/server/models"
+)
+
+const (
+	// reminderLease - How long a claimed reminder is held back from other workers.
+	// Only a worker that dies mid-send leaves a reminder to be picked up again once this expires.
+	reminderLease = 10 * time.Minute
+	// reminderRetryDelay - Backoff per failed attempt before a reminder is retried
+	reminderRetryDelay  = time.Minute
+	maxReminderAttempts = 5
+)
+
+// StartReminderWorker - Sends due reminders every interval until ctx is cancelled.
+// A reminder that is being sent when ctx is cancelled is finished first; wg is released once the worker has stopped.
+func StartReminderWorker(ctx context.Context, wg *sync.WaitGroup, interval time.Duration) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		log.Printf("Reminder worker started, polling every %s", interval)
+		for {
+			sendDueReminders(ctx)
+
+			select {
+			case <-ctx.Done():
+				log.Println("Reminder worker stopped.")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// sendDueReminders - Claims and sends reminders one at a time until none are due or ctx is cancelled
+func sendDueReminders(ctx context.Context) {
+	for ctx.Err() == nil {
+		// In-flight work uses its own context so shutting down never abandons a claimed reminder
+		workCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		reminder, err := claimReminder(workCtx)
+		if err == nil {
+			deliverReminder(workCtx, reminder)
+		}
+		cancel()
+
+		if err == mongo.ErrNoDocuments {
+			return
+		}
+		if err != nil {
+			log.Printf("Error claiming reminder: %v", err)
+			return
+		}
+	}
+}
+
+// claimReminder - Atomically takes the oldest due reminder so that no other worker can send it
+func claimReminder(ctx context.Context) (model.Reminder, error) {
+	now := time.Now().UTC()
+	filter := bson.M{
+		"status":       bson.M{"$in": []model.ReminderStatus{model.ReminderPending, model.ReminderSending}},
+		"fire_at":      bson.M{"$lte": now},
+		"locked_until": bson.M{"$lte": now},
+	}
+	update := bson.M{
+		"$set": bson.M{"status": model.ReminderSending, "locked_until": now.Add(reminderLease)},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "fire_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var reminder model.Reminder
+	err := database.GetReminderCollection().FindOneAndUpdate(ctx, filter, update, opts).Decode(&reminder)
+	return reminder, err
+}
+
+// deliverReminder - Emails the task owner and records the outcome on the claimed reminder.
+// Reminders for tasks that were closed, deleted, trashed or rescheduled in the meantime are skipped.
+func deliverReminder(ctx context.Context, reminder model.Reminder) {
+	var task model.Task
+	err := database.GetTaskCollection().FindOne(ctx, helper.NotDeleted(bson.M{"_id": reminder.TaskID, "user_id": reminder.UserID})).Decode(&task)
+	if err == mongo.ErrNoDocuments || (err == nil && !reminderStillApplies(task, reminder)) {
+		finishReminder(ctx, reminder, model.ReminderSkipped, "")
+		return
+	}
+	if err != nil {
+		retryReminder(ctx, reminder, err)
+		return
+	}
+
+	var user model.User
+	if err := database.GetUserCollection().FindOne(ctx, bson.M{"user_id": reminder.UserID}).Decode(&user); err != nil || user.Email == nil {
+		finishReminder(ctx, reminder, model.ReminderFailed, "user email not found")
+		return
+	}
+
+	subject := "Reminder: " + task.Title
+	body := fmt.Sprintf("Your task \"%s\" is due at %s.", task.Title, task.DueAt.UTC().Format("Mon 2 Jan 2006 15:04 MST"))
+	if err := helper.SendEmail(*user.Email, subject, body); err != nil {
+		retryReminder(ctx, reminder, err)
+		return
+	}
+
+	finishReminder(ctx, reminder, model.ReminderSent, "")
+}
+
+// reminderStillApplies - Checks the task is still open and due when the reminder was scheduled for
+func reminderStillApplies(task model.Task, reminder model.Reminder) bool {
+	if task.DueAt == nil || task.Status.IsClosed() {
+		return false
+	}
+	return task.DueAt.Add(-time.Duration(reminder.OffsetMinutes) * time.Minute).Equal(reminder.FireAt)
+}
+
+// finishReminder - Records the final state of a reminder so it is never picked up again
+func finishReminder(ctx context.Context, reminder model.Reminder, status model.ReminderStatus, reason string) {
+	set := bson.M{"status": status, "last_error": reason}
+	if status == model.ReminderSent {
+		set["sent_at"] = time.Now().UTC()
+	}
+
+	if _, err := database.GetReminderCollection().UpdateOne(ctx, bson.M{"_id": reminder.ID}, bson.M{"$set": set}); err != nil {
+		log.Printf("Error recording reminder %s as %s: %v", reminder.ID.Hex(), status, err)
+	}
+}
+
+// retryReminder - Puts a failed reminder back in the queue with a backoff, giving up after maxReminderAttempts
+func retryReminder(ctx context.Context, reminder model.Reminder, cause error) {
+	log.Printf("Error sending reminder %s: %v", reminder.ID.Hex(), cause)
+	if reminder.Attempts >= maxReminderAttempts {
+		finishReminder(ctx, reminder, model.ReminderFailed, cause.Error())
+		return
+	}
+
+	retryAt := time.Now().UTC().Add(time.Duration(reminder.Attempts) * reminderRetryDelay)
+	_, err := database.GetReminderCollection().UpdateOne(ctx, bson.M{"_id": reminder.ID}, bson.M{"$set": bson.M{
+		"status":       model.ReminderPending,
+		"locked_until": retryAt,
+		"last_error":   cause.Error(),
+	}})
+	if err != nil {
+		log.Printf("Error requeueing reminder %s: %v", reminder.ID.Hex(), err)
+	}
+}
@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	helper "task-manager/server/helpers"
+)
+
+// StartTrashPurger - Every interval, permanently deletes tasks that have been in the trash for longer than retention.
+// Runs until ctx is cancelled; wg is released once it has stopped.
+func StartTrashPurger(ctx context.Context, wg *sync.WaitGroup, interval time.Duration, retention time.Duration) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		log.Printf("Trash purger started, removing tasks trashed more than %s ago", retention)
+		for {
+			purgeExpiredTrash(ctx, retention)
+
+			select {
+			case <-ctx.Done():
+				log.Println("Trash purger stopped.")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// purgeExpiredTrash - Removes every task trashed before the retention cutoff
+func purgeExpiredTrash(ctx context.Context, retention time.Duration) {
+	cutoff := time.Now().UTC().Add(-retention)
+	purged, err := helper.PurgeTasks(ctx, bson.M{"deleted_at": bson.M{"$lt": cutoff}})
+	if err != nil {
+		log.Printf("Error purging trash: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("Purged %d tasks from the trash", purged)
+	}
+}
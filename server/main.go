@@ -7,16 +7,60 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"sync"
 	"syscall"
 	"time"
 
 	"task-manager/server/database"
+	helper "task-manager/server/helpers"
+	"task-manager/server/jobs"
 	"task-manager/server/routes"
+	"task-manager/server/storage"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	database.Connect()
+	helper.LoadHashKey()
+
+	migrateCtx, cancelMigrate := context.WithTimeout(context.Background(), 30*time.Second)
+	if err := database.MigrateTaskStatuses(migrateCtx); err != nil {
+		log.Printf("Error migrating task statuses: %v", err)
+	}
+	if err := database.EnsureIndexes(migrateCtx); err != nil {
+		log.Printf("Error creating indexes: %v", err)
+	}
+	cancelMigrate()
+
+	reminderInterval := 30 * time.Second
+	if seconds, err := strconv.Atoi(os.Getenv("REMINDER_POLL_SECONDS")); err == nil && seconds > 0 {
+		reminderInterval = time.Duration(seconds) * time.Second
+	}
+
+	rebalanceInterval := 10 * time.Minute
+	if minutes, err := strconv.Atoi(os.Getenv("RANK_REBALANCE_MINUTES")); err == nil && minutes > 0 {
+		rebalanceInterval = time.Duration(minutes) * time.Minute
+	}
+
+	trashRetention := 30 * 24 * time.Hour
+	if days, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS")); err == nil && days > 0 {
+		trashRetention = time.Duration(days) * 24 * time.Hour
+	}
+
+	attachmentStore, err := storage.NewBlobStore(os.Getenv("ATTACHMENT_STORAGE"), os.Getenv("ATTACHMENT_DIR"))
+	if err != nil {
+		log.Fatalf("Error setting up attachment storage: %v", err)
+	}
+	storage.Attachments = attachmentStore
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	jobs.StartReminderWorker(workerCtx, &workers, reminderInterval)
+	jobs.StartRankRebalancer(workerCtx, &workers, rebalanceInterval)
+	jobs.StartTrashPurger(workerCtx, &workers, time.Hour, trashRetention)
+
 	router := gin.New()
 	router.Use(gin.Logger())
 	routes.SetupRoutes(router)
@@ -52,6 +96,18 @@ func main() {
 		log.Printf("Error during server shutdown: %v", err)
 	}
 
+	stopWorkers()
+	drained := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		log.Println("Timed out waiting for background workers to stop")
+	}
+
 	if err := database.MongoClient.Disconnect(ctx); err != nil {
 		log.Printf("Error disconnecting MongoDB: %v", err)
 	}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	helper "task-manager/server/helpers"
+)
+
+// maxBatchBodyBytes - The largest batch body the middleware will read to count its operations
+const maxBatchBodyBytes = 1 << 20
+
+// BatchRateLimitMiddleware creates a rate limiter for a batch route that charges one token per operation
+// in the request body, so a batch of 50 updates costs the same as 50 single updates would.
+// capacity must be at least the largest batch the route accepts.
+func BatchRateLimitMiddleware(rate float64, capacity int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBatchBodyBytes))
+		if err != nil {
+			helper.RespondWithError(c, http.StatusRequestEntityTooLarge, "Request body too large", err.Error())
+			c.Abort()
+			return
+		}
+		// Put the body back for the handler to bind
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		var batch struct {
+			Operations []json.RawMessage `json:"operations"`
+		}
+		cost := int64(1)
+		if err := json.Unmarshal(body, &batch); err == nil && len(batch.Operations) > 1 {
+			cost = int64(len(batch.Operations))
+		}
+
+		bucket := getRateLimiter(c.FullPath(), c.ClientIP(), rate, capacity)
+		if _, ok := bucket.TakeMaxDuration(cost, 0); !ok {
+			helper.RespondWithError(c, http.StatusTooManyRequests, "Rate limit exceeded", "Too many operations for this route")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	helper "task-manager/server/helpers"
+	model "task-manager/server/models"
+)
+
+// Workspace - Resolves the workspace the request works in from the X-Workspace-ID header or the workspace_id
+// query parameter, falling back to the user's personal workspace, and checks the user is a member of it or,
+// for someone else's personal workspace, was shared or assigned something in it.
+// Handlers find it in the request context, which scopes their task and project queries to it.
+func Workspace() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, username, valid := helper.GetUserDetails(c)
+		if !valid {
+			helper.RespondWithError(c, http.StatusUnauthorized, "User not authorized", "UID or Username not found in context")
+			c.Abort()
+			return
+		}
+
+		raw := c.GetHeader("X-Workspace-ID")
+		if raw == "" {
+			raw = c.Query("workspace_id")
+		}
+
+		ctx := c.Request.Context()
+		var workspaceID primitive.ObjectID
+		var role model.WorkspaceRole
+		if raw == "" {
+			workspace, err := helper.EnsurePersonalWorkspace(ctx, userID, username)
+			if err != nil {
+				helper.RespondWithError(c, http.StatusInternalServerError, "Error fetching workspace", err.Error())
+				c.Abort()
+				return
+			}
+			workspaceID, role = workspace.ID, model.WorkspaceOwner
+		} else {
+			id, err := primitive.ObjectIDFromHex(raw)
+			if err != nil {
+				helper.RespondWithError(c, http.StatusBadRequest, "Invalid workspace ID format", err.Error())
+				c.Abort()
+				return
+			}
+			if role, err = helper.FindWorkspaceRole(ctx, id, userID); err != nil {
+				helper.RespondWithRequestError(c, err, "Error fetching workspace")
+				c.Abort()
+				return
+			}
+			workspaceID = id
+		}
+
+		c.Request = c.Request.WithContext(helper.WithWorkspace(ctx, workspaceID, role))
+		c.Next()
+	}
+}
@@ -0,0 +1,17 @@
+package model
+
+type AssignmentStatus string
+
+const (
+	AssignmentPending  AssignmentStatus = "pending"
+	AssignmentAccepted AssignmentStatus = "accepted"
+	AssignmentDeclined AssignmentStatus = "declined"
+)
+
+// ActiveAssignments - Assignment statuses that give the assignee access to the task
+var ActiveAssignments = []AssignmentStatus{AssignmentPending, AssignmentAccepted}
+
+// IsAssignedTo - Reports whether the task is assigned to the user and they have not declined it
+func (t Task) IsAssignedTo(userID string) bool {
+	return t.AssigneeID != nil && *t.AssigneeID == userID && t.AssignmentStatus != AssignmentDeclined
+}
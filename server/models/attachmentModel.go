@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Attachment describes a file attached to a task. The file itself is kept in blob storage under its ID.
+type Attachment struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	Name        string             `bson:"name" json:"name"`
+	ContentType string             `bson:"content_type" json:"content_type"`
+	Size        int64              `bson:"size" json:"size"`
+	UploadedBy  string             `bson:"uploaded_by" json:"uploaded_by"`
+	Created     time.Time          `bson:"created_at" json:"created_at"`
+}
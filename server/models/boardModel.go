@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BoardColumn is a column of a kanban board. A task sits in the column that lists its status.
+// WIPLimit caps how many tasks the column may hold, with 0 meaning no limit.
+type BoardColumn struct {
+	ID       primitive.ObjectID `bson:"_id" json:"id"`
+	Name     string             `bson:"name" json:"name" validate:"required,min=1,max=50"`
+	Statuses []TaskStatus       `bson:"statuses" json:"statuses" validate:"required,min=1,dive,oneof=todo in_progress blocked done cancelled"`
+	WIPLimit int                `bson:"wip_limit" json:"wip_limit" validate:"min=0,max=1000"`
+}
+
+// Board is the kanban view of a project. Each project has at most one board.
+type Board struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID    string             `bson:"user_id" json:"user_id" validate:"required"`
+	ProjectID primitive.ObjectID `bson:"project_id" json:"project_id"`
+	Name      string             `bson:"name" json:"name" validate:"required,min=1,max=100"`
+	Columns   []BoardColumn      `bson:"columns" json:"columns" validate:"required,min=1,max=20,dive"`
+	Created   time.Time          `bson:"created_at" json:"created_at"`
+	Updated   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// ColumnFor - Returns the column that holds tasks with the given status, or nil if none does
+func (b Board) ColumnFor(status TaskStatus) *BoardColumn {
+	for i := range b.Columns {
+		for _, columnStatus := range b.Columns[i].Statuses {
+			if columnStatus == status {
+				return &b.Columns[i]
+			}
+		}
+	}
+	return nil
+}
+
+// BoardColumnView is a board column with the tasks currently in it
+type BoardColumnView struct {
+	BoardColumn
+	Count int64  `json:"count"`
+	Full  bool   `json:"full"`
+	Tasks []Task `json:"tasks"`
+}
+
+// BoardView is a whole board with its tasks grouped by column
+type BoardView struct {
+	Board   Board             `json:"board"`
+	Columns []BoardColumnView `json:"columns"`
+}
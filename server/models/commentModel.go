@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CommentEdit is an earlier version of a comment's body, replaced at Edited
+type CommentEdit struct {
+	Body   string    `bson:"body" json:"body"`
+	Edited time.Time `bson:"edited_at" json:"edited_at"`
+}
+
+// Comment is a note in a task's discussion thread. Bodies are Markdown, stored as written and rendered by clients.
+// Each edit keeps the body it replaced in Edits, oldest first.
+type Comment struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	TaskID   primitive.ObjectID `bson:"task_id" json:"task_id"`
+	UserID   string             `bson:"user_id" json:"-"`
+	AuthorID string             `bson:"author_id" json:"author_id"`
+	Author   string             `bson:"author" json:"author"`
+	Body     string             `bson:"body" json:"body" validate:"required,min=1,max=10000"`
+	Edits    []CommentEdit      `bson:"edits,omitempty" json:"edits,omitempty"`
+	Created  time.Time          `bson:"created_at" json:"created_at"`
+	Updated  *time.Time         `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
+}
+
+// CommentPage - One page of a task's comments, oldest first, with the cursor for the next page
+type CommentPage struct {
+	Comments   []Comment `json:"comments"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
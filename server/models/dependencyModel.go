@@ -0,0 +1,32 @@
+package model
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// DependencyEdge links a prerequisite to a task that is blocked by it
+type DependencyEdge struct {
+	From primitive.ObjectID `json:"from"`
+	To   primitive.ObjectID `json:"to"`
+}
+
+// Dependencies are the tasks a task is blocked by and the tasks it blocks
+type Dependencies struct {
+	BlockedBy []Task `json:"blocked_by"`
+	Blocks    []Task `json:"blocks"`
+}
+
+// DependencyGraph is the dependency graph of a project. Tasks come in topological order, every task after its prerequisites,
+// and Edges only link tasks of the project.
+type DependencyGraph struct {
+	Tasks []Task           `json:"tasks"`
+	Edges []DependencyEdge `json:"edges"`
+}
+
+// IsBlockedBy - Reports whether the task depends on the prerequisite
+func (t Task) IsBlockedBy(prerequisite primitive.ObjectID) bool {
+	for _, id := range t.BlockedBy {
+		if id == prerequisite {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package model
+
+import "encoding/json"
+
+// Nullable distinguishes a JSON field that was omitted from one explicitly set to null,
+// so partial updates can clear a value instead of silently ignoring it.
+type Nullable[T any] struct {
+	Set   bool
+	Value *T
+}
+
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	if string(data) == "null" {
+		n.Value = nil
+		return nil
+	}
+
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	n.Value = &value
+	return nil
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type OperationAction string
+
+const (
+	OperationCreate    OperationAction = "create"
+	OperationUpdate    OperationAction = "update"
+	OperationDelete    OperationAction = "delete"
+	OperationDeleteAll OperationAction = "delete_all"
+)
+
+// Operation is an entry in a user's undo log. It keeps what is needed to reverse the operation and
+// to replay it again. Stamp is the updated_at (create, update) or deleted_at (delete, delete_all)
+// the tasks must still carry; if they do not, the tasks changed since and the operation conflicts.
+// OwnerID is whose tasks were changed, which differs from UserID for tasks shared with the user.
+type Operation struct {
+	ID          primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	UserID      string               `bson:"user_id" json:"-"`
+	OwnerID     string               `bson:"owner_id,omitempty" json:"-"`
+	WorkspaceID primitive.ObjectID   `bson:"workspace_id,omitempty" json:"-"`
+	Action      OperationAction      `bson:"action" json:"action"`
+	TaskID      *primitive.ObjectID  `bson:"task_id,omitempty" json:"task_id,omitempty"`
+	Before      *Task                `bson:"before,omitempty" json:"-"`
+	After       *Task                `bson:"after,omitempty" json:"-"`
+	Children    string               `bson:"children,omitempty" json:"-"`
+	TaskIDs     []primitive.ObjectID `bson:"task_ids,omitempty" json:"-"`
+	Promoted    []primitive.ObjectID `bson:"promoted,omitempty" json:"-"`
+	Stamp       time.Time            `bson:"stamp" json:"-"`
+	Undone      bool                 `bson:"undone" json:"undone"`
+	Created     time.Time            `bson:"created_at" json:"created_at"`
+}
+
+// Owner - The user whose tasks the operation changed. Entries logged before tasks could be shared
+// have no OwnerID and only ever changed the user's own tasks.
+func (o Operation) Owner() string {
+	if o.OwnerID != "" {
+		return o.OwnerID
+	}
+	return o.UserID
+}
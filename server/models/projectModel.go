@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Project groups tasks into a list. Folders are projects that hold other projects instead of tasks.
+type Project struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	WorkspaceID primitive.ObjectID  `bson:"workspace_id,omitempty" json:"workspace_id"`
+	UserID      string              `bson:"user_id" json:"user_id" validate:"required"`
+	Name        string              `bson:"name" json:"name" validate:"required,min=1,max=100"`
+	IsFolder    bool                `bson:"is_folder" json:"is_folder"`
+	ParentID    *primitive.ObjectID `bson:"parent_id,omitempty" json:"parent_id,omitempty"`
+	Archived    bool                `bson:"archived" json:"archived"`
+	Created     time.Time           `bson:"created_at" json:"created_at"`
+	Updated     time.Time           `bson:"updated_at" json:"updated_at"`
+}
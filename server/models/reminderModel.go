@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReminderStatus string
+
+const (
+	ReminderPending ReminderStatus = "pending"
+	ReminderSending ReminderStatus = "sending"
+	ReminderSent    ReminderStatus = "sent"
+	ReminderSkipped ReminderStatus = "skipped"
+	ReminderFailed  ReminderStatus = "failed"
+)
+
+// Reminder is an entry in the persistent reminder queue: an email to send
+// OffsetMinutes before a task is due. LockedUntil holds a claimed reminder
+// back from other workers, and delays the retry of one that failed.
+type Reminder struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	TaskID        primitive.ObjectID `bson:"task_id" json:"task_id"`
+	UserID        string             `bson:"user_id" json:"user_id"`
+	OffsetMinutes int                `bson:"offset_minutes" json:"offset_minutes"`
+	FireAt        time.Time          `bson:"fire_at" json:"fire_at"`
+	Status        ReminderStatus     `bson:"status" json:"status"`
+	Attempts      int                `bson:"attempts" json:"attempts"`
+	LockedUntil   time.Time          `bson:"locked_until" json:"locked_until"`
+	LastError     string             `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	SentAt        *time.Time         `bson:"sent_at,omitempty" json:"sent_at,omitempty"`
+	Created       time.Time          `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RevisionAction string
+
+const (
+	RevisionCreate RevisionAction = "create"
+	RevisionUpdate RevisionAction = "update"
+	RevisionRevert RevisionAction = "revert"
+)
+
+// FieldChange is the value of one task field before and after a revision.
+// A nil value means the field was not set.
+type FieldChange struct {
+	Field string      `bson:"field" json:"field"`
+	Old   interface{} `bson:"old" json:"old"`
+	New   interface{} `bson:"new" json:"new"`
+}
+
+// Revision records one change to a task: who made it, when and which fields it changed.
+// Revisions of a task are numbered from 1, in the order they were made.
+type Revision struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	TaskID     primitive.ObjectID `bson:"task_id" json:"task_id"`
+	UserID     string             `bson:"user_id" json:"user_id"`
+	Number     int                `bson:"number" json:"number"`
+	Action     RevisionAction     `bson:"action" json:"action"`
+	AuthorID   string             `bson:"author_id" json:"author_id"`
+	Author     string             `bson:"author" json:"author"`
+	Changes    []FieldChange      `bson:"changes" json:"changes"`
+	RevertedTo int                `bson:"reverted_to,omitempty" json:"reverted_to,omitempty"`
+	Created    time.Time          `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SavedFilter is a named task query a user can open as a list with GET /tasks?view=<id>.
+// Query is written in the task filter language and Sort uses the same syntax as ?sort=.
+// Built-in smart lists have a Key instead of an ID and are not stored.
+type SavedFilter struct {
+	ID      primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Key     string             `bson:"-" json:"key,omitempty"`
+	UserID  string             `bson:"user_id" json:"user_id,omitempty"`
+	Name    string             `bson:"name" json:"name" validate:"required,min=1,max=50"`
+	Query   string             `bson:"query" json:"query" validate:"max=500"`
+	Sort    string             `bson:"sort,omitempty" json:"sort,omitempty" validate:"max=100"`
+	Icon    string             `bson:"icon,omitempty" json:"icon,omitempty" validate:"max=30"`
+	BuiltIn bool               `bson:"-" json:"built_in"`
+	Created time.Time          `bson:"created_at" json:"created_at"`
+	Updated time.Time          `bson:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ShareRole string
+
+const (
+	RoleViewer ShareRole = "viewer"
+	RoleEditor ShareRole = "editor"
+	RoleOwner  ShareRole = "owner"
+)
+
+// shareRoleLevels - Each role can do everything the roles below it can
+var shareRoleLevels = map[ShareRole]int{RoleViewer: 1, RoleEditor: 2, RoleOwner: 3}
+
+// Allows - Reports whether the role grants at least the access of need
+func (r ShareRole) Allows(need ShareRole) bool {
+	return shareRoleLevels[r] >= shareRoleLevels[need]
+}
+
+type ShareResource string
+
+const (
+	ShareTask    ShareResource = "task"
+	ShareProject ShareResource = "project"
+)
+
+// Share grants another user access to a task or a project. Sharing a project shares all of its tasks.
+// Viewers can read, editors can also change tasks, and owners can also delete them and manage shares.
+type Share struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	WorkspaceID primitive.ObjectID `bson:"workspace_id,omitempty" json:"workspace_id"`
+	Resource    ShareResource      `bson:"resource" json:"resource"`
+	ResourceID  primitive.ObjectID `bson:"resource_id" json:"resource_id"`
+	OwnerID     string             `bson:"owner_id" json:"owner_id"`
+	UserID      string             `bson:"user_id" json:"user_id"`
+	Username    string             `bson:"username" json:"username"`
+	Role        ShareRole          `bson:"role" json:"role" validate:"required,oneof=viewer editor owner"`
+	GrantedBy   string             `bson:"granted_by" json:"granted_by"`
+	Created     time.Time          `bson:"created_at" json:"created_at"`
+	Updated     time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// SharedItem - A share the user received, with the task or project it gives access to
+type SharedItem struct {
+	Share   Share    `json:"share"`
+	Task    *Task    `json:"task,omitempty"`
+	Project *Project `json:"project,omitempty"`
+}
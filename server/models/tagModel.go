@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tag is an entry in a user's tag catalogue. Tasks reference tags by name.
+type Tag struct {
+	ID      primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID  string             `bson:"user_id" json:"user_id" validate:"required"`
+	Name    string             `bson:"name" json:"name" validate:"required,min=1,max=30"`
+	Color   string             `bson:"color,omitempty" json:"color,omitempty" validate:"omitempty,hexcolor"`
+	Created time.Time          `bson:"created_at" json:"created_at"`
+	Updated time.Time          `bson:"updated_at" json:"updated_at"`
+}
@@ -1,17 +1,224 @@
 package model
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+type TaskStatus string
+
+const (
+	StatusTodo       TaskStatus = "todo"
+	StatusInProgress TaskStatus = "in_progress"
+	StatusBlocked    TaskStatus = "blocked"
+	StatusDone       TaskStatus = "done"
+	StatusCancelled  TaskStatus = "cancelled"
+)
+
+// TaskTransitions - The workflow: which statuses a task may move to from its current one
+var TaskTransitions = map[TaskStatus][]TaskStatus{
+	StatusTodo:       {StatusInProgress, StatusBlocked, StatusDone, StatusCancelled},
+	StatusInProgress: {StatusTodo, StatusBlocked, StatusDone, StatusCancelled},
+	StatusBlocked:    {StatusTodo, StatusInProgress, StatusCancelled},
+	StatusDone:       {StatusTodo, StatusInProgress},
+	StatusCancelled:  {StatusTodo},
+}
+
+// ClosedStatuses - Statuses that count as finished for overdue and open-task queries
+var ClosedStatuses = []TaskStatus{StatusDone, StatusCancelled}
+
+// CanTransitionTo - Reports whether the workflow allows moving from s to next
+func (s TaskStatus) CanTransitionTo(next TaskStatus) bool {
+	if s == next {
+		return true
+	}
+	for _, allowed := range TaskTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsClosed - Reports whether the status is done or cancelled
+func (s TaskStatus) IsClosed() bool {
+	for _, closed := range ClosedStatuses {
+		if s == closed {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmarshalBSONValue - Decodes the status, mapping legacy boolean values to todo/done
+func (s *TaskStatus) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	raw := bson.RawValue{Type: t, Value: data}
+	switch t {
+	case bson.TypeString:
+		*s = TaskStatus(raw.StringValue())
+	case bson.TypeBoolean:
+		if raw.Boolean() {
+			*s = StatusDone
+		} else {
+			*s = StatusTodo
+		}
+	case bson.TypeNull, bson.TypeUndefined:
+		*s = StatusTodo
+	default:
+		return fmt.Errorf("cannot decode %v into a task status", t)
+	}
+	return nil
+}
+
+type TaskPriority int
+
+const (
+	PriorityNone TaskPriority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+	PriorityUrgent
+)
+
+// priorityNames - Priorities are stored as numbers so they sort by importance, but exchanged as names
+var priorityNames = []string{"none", "low", "medium", "high", "urgent"}
+
+func (p TaskPriority) String() string {
+	if p < PriorityNone || p > PriorityUrgent {
+		return fmt.Sprintf("TaskPriority(%d)", int(p))
+	}
+	return priorityNames[p]
+}
+
+// ParseTaskPriority - Converts a priority name into its TaskPriority
+func ParseTaskPriority(name string) (TaskPriority, error) {
+	for i, priorityName := range priorityNames {
+		if name == priorityName {
+			return TaskPriority(i), nil
+		}
+	}
+	return PriorityNone, fmt.Errorf("unknown priority %q, expected one of none, low, medium, high, urgent", name)
+}
+
+func (p TaskPriority) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+func (p *TaskPriority) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("priority must be a string: %w", err)
+	}
+
+	priority, err := ParseTaskPriority(name)
+	if err != nil {
+		return err
+	}
+	*p = priority
+	return nil
+}
+
+// ChecklistItem is one step of a task's checklist. Items are kept in Position order.
+type ChecklistItem struct {
+	ID       primitive.ObjectID `bson:"_id" json:"id"`
+	Text     string             `bson:"text" json:"text" validate:"required,min=1,max=200"`
+	Done     bool               `bson:"done" json:"done"`
+	Position int                `bson:"position" json:"position"`
+}
+
+// ChecklistProgress summarises how much of a checklist is done, e.g. 3 of 5
+type ChecklistProgress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// SubtaskRollup aggregates the subtasks nested under a task, at any depth
+type SubtaskRollup struct {
+	Total     int        `json:"total"`
+	Done      int        `json:"done"`
+	NextDueAt *time.Time `json:"next_due_at,omitempty"`
+}
+
+type RecurrenceMode string
+
+const (
+	RepeatFromDue        RecurrenceMode = "due"
+	RepeatFromCompletion RecurrenceMode = "completion"
+)
+
+// Recurrence makes a task repeat according to an RFC 5545 RRULE evaluated in Timezone.
+// Each occurrence is its own task; the series fields link them together.
+type Recurrence struct {
+	RRule       string             `bson:"rrule" json:"rrule" validate:"required,max=500"`
+	Timezone    string             `bson:"timezone" json:"timezone" validate:"omitempty,timezone"`
+	Mode        RecurrenceMode     `bson:"mode" json:"mode" validate:"omitempty,oneof=due completion"`
+	SeriesID    primitive.ObjectID `bson:"series_id" json:"series_id"`
+	SeriesStart time.Time          `bson:"series_start" json:"series_start"`
+	Occurrence  int                `bson:"occurrence" json:"occurrence"`
+}
+
 type Task struct {
-	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	UserID   string             `bson:"user_id" json:"user_id" validate:"required"`
-	Username string             `bson:"username" json:"username" validate:"required"`
-	Title    string             `bson:"title" json:"title" validate:"required,min=1,max=140"`
-	Status   bool               `bson:"status" json:"status"`
-	Created  time.Time          `bson:"created_at" json:"created_at"`
-	Updated  time.Time          `bson:"updated_at" json:"updated_at"`
+	ID                    primitive.ObjectID   `bson:"_id,omitempty" json:"id,omitempty"`
+	WorkspaceID           primitive.ObjectID   `bson:"workspace_id,omitempty" json:"workspace_id"`
+	UserID                string               `bson:"user_id" json:"user_id" validate:"required"`
+	Username              string               `bson:"username" json:"username" validate:"required"`
+	Title                 string               `bson:"title" json:"title" validate:"required,min=1,max=140"`
+	ProjectID             *primitive.ObjectID  `bson:"project_id,omitempty" json:"project_id,omitempty"`
+	ParentID              *primitive.ObjectID  `bson:"parent_id,omitempty" json:"parent_id,omitempty"`
+	Rank                  string               `bson:"rank,omitempty" json:"rank,omitempty"`
+	Tags                  []string             `bson:"tags,omitempty" json:"tags,omitempty" validate:"omitempty,max=20,dive,min=1,max=30"`
+	Priority              TaskPriority         `bson:"priority" json:"priority" validate:"min=0,max=4"`
+	Status                TaskStatus           `bson:"status" json:"status" validate:"required,oneof=todo in_progress blocked done cancelled"`
+	Checklist             []ChecklistItem      `bson:"checklist,omitempty" json:"checklist,omitempty" validate:"omitempty,max=100,dive"`
+	ChecklistAutoComplete bool                 `bson:"checklist_auto_complete" json:"checklist_auto_complete"`
+	DueAt                 *time.Time           `bson:"due_at,omitempty" json:"due_at,omitempty"`
+	StartAt               *time.Time           `bson:"start_at,omitempty" json:"start_at,omitempty"`
+	ReminderOffsets       []int                `bson:"reminder_offsets,omitempty" json:"reminder_offsets,omitempty" validate:"omitempty,max=10,dive,min=1,max=40320"`
+	Recurrence            *Recurrence          `bson:"recurrence,omitempty" json:"recurrence,omitempty"`
+	NextOccurrenceID      *primitive.ObjectID  `bson:"next_occurrence_id,omitempty" json:"next_occurrence_id,omitempty"`
+	AssigneeID            *string              `bson:"assignee_id,omitempty" json:"assignee_id,omitempty"`
+	AssignmentStatus      AssignmentStatus     `bson:"assignment_status,omitempty" json:"assignment_status,omitempty"`
+	AssignedBy            string               `bson:"assigned_by,omitempty" json:"assigned_by,omitempty"`
+	AssignedAt            *time.Time           `bson:"assigned_at,omitempty" json:"assigned_at,omitempty"`
+	BlockedBy             []primitive.ObjectID `bson:"blocked_by,omitempty" json:"blocked_by,omitempty"`
+	ResumeStatus          TaskStatus           `bson:"resume_status,omitempty" json:"-"`
+	CommentCount          int                  `bson:"comment_count,omitempty" json:"comment_count"`
+	Attachments           []Attachment         `bson:"attachments,omitempty" json:"attachments,omitempty"`
+	CompletedAt           *time.Time           `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+	DeletedAt             *time.Time           `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	Created               time.Time            `bson:"created_at" json:"created_at"`
+	Updated               time.Time            `bson:"updated_at" json:"updated_at"`
+
+	// Filled in when a task is returned together with its subtasks
+	Children []Task         `bson:"-" json:"children,omitempty"`
+	Subtasks *SubtaskRollup `bson:"-" json:"subtasks,omitempty"`
+}
+
+// ChecklistProgress - Counts the checked items of the task's checklist, or nil when it has none
+func (t Task) ChecklistProgress() *ChecklistProgress {
+	if len(t.Checklist) == 0 {
+		return nil
+	}
+
+	progress := &ChecklistProgress{Total: len(t.Checklist)}
+	for _, item := range t.Checklist {
+		if item.Done {
+			progress.Done++
+		}
+	}
+	return progress
+}
+
+// MarshalJSON - Encodes the task along with its derived checklist progress
+func (t Task) MarshalJSON() ([]byte, error) {
+	type taskFields Task
+	return json.Marshal(struct {
+		taskFields
+		ChecklistProgress *ChecklistProgress `json:"checklist_progress,omitempty"`
+	}{taskFields(t), t.ChecklistProgress()})
 }
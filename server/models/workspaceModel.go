@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type WorkspaceRole string
+
+const (
+	WorkspaceOwner  WorkspaceRole = "owner"
+	WorkspaceAdmin  WorkspaceRole = "admin"
+	WorkspaceMember WorkspaceRole = "member"
+	WorkspaceGuest  WorkspaceRole = "guest"
+)
+
+// workspaceRoleLevels - Each role can do everything the roles below it can
+var workspaceRoleLevels = map[WorkspaceRole]int{WorkspaceGuest: 1, WorkspaceMember: 2, WorkspaceAdmin: 3, WorkspaceOwner: 4}
+
+// Allows - Reports whether the role grants at least the access of need
+func (r WorkspaceRole) Allows(need WorkspaceRole) bool {
+	return workspaceRoleLevels[r] >= workspaceRoleLevels[need]
+}
+
+// Workspace is the boundary tasks and projects live in. Every user has a personal workspace, created the first
+// time they need one, that holds only them; team workspaces hold their members. Owners and admins manage the
+// members, members work on all of the workspace's tasks and guests only see what is shared with or assigned to them.
+type Workspace struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Name     string             `bson:"name" json:"name" validate:"required,min=1,max=100"`
+	Personal bool               `bson:"personal" json:"personal"`
+	OwnerID  string             `bson:"owner_id" json:"owner_id"`
+	Migrated bool               `bson:"migrated,omitempty" json:"-"`
+	Created  time.Time          `bson:"created_at" json:"created_at"`
+	Updated  time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// Membership places a user in a workspace with a role
+type Membership struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	WorkspaceID primitive.ObjectID `bson:"workspace_id" json:"workspace_id"`
+	UserID      string             `bson:"user_id" json:"user_id"`
+	Username    string             `bson:"username" json:"username"`
+	Role        WorkspaceRole      `bson:"role" json:"role" validate:"required,oneof=owner admin member guest"`
+	Created     time.Time          `bson:"created_at" json:"created_at"`
+	Updated     time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// WorkspaceView - A workspace along with the user's role in it
+type WorkspaceView struct {
+	Workspace
+	Role WorkspaceRole `json:"role"`
+}
@@ -5,6 +5,7 @@ import (
 
 	controller "task-manager/server/controllers"
 	middleware "task-manager/server/middleware"
+	model "task-manager/server/models"
 )
 
 func SetupRoutes(router *gin.Engine) {
@@ -27,11 +28,119 @@ func SetupRoutes(router *gin.Engine) {
 	router.GET("/users", middleware.RateLimitMiddleware(3, 6), controller.GetUsers())
 	router.GET("/users/:user_id", middleware.RateLimitMiddleware(3, 5), controller.GetUser())
 
+	// Workspace Routes
+	router.GET("/workspaces", middleware.RateLimitMiddleware(3, 6), controller.GetWorkspaces())
+	router.POST("/workspaces", middleware.RateLimitMiddleware(0.5, 2), controller.PostWorkspace())
+	router.PUT("/workspaces/:workspace_id", middleware.RateLimitMiddleware(1, 3), controller.UpdateWorkspace())
+	router.DELETE("/workspaces/:workspace_id", middleware.RateLimitMiddleware(0.5, 1), controller.DeleteWorkspace())
+	router.GET("/workspaces/:workspace_id/members", middleware.RateLimitMiddleware(3, 6), controller.GetMembers())
+	router.POST("/workspaces/:workspace_id/members", middleware.RateLimitMiddleware(1, 3), controller.AddMember())
+	router.PUT("/workspaces/:workspace_id/members/:user_id", middleware.RateLimitMiddleware(1, 3), controller.UpdateMember())
+	router.DELETE("/workspaces/:workspace_id/members/:user_id", middleware.RateLimitMiddleware(1, 3), controller.RemoveMember())
+
+	// Everything below works in the workspace picked by the X-Workspace-ID header, the personal one by default
+	router.Use(middleware.Workspace())
+
 	// Task Routes
 	router.GET("/tasks", middleware.RateLimitMiddleware(10, 20), controller.GetTasks())
+	router.GET("/tasks/search", middleware.RateLimitMiddleware(5, 10), controller.SearchTasks())
 	router.GET("/tasks/:id", middleware.RateLimitMiddleware(3, 6), controller.GetTaskByID())
 	router.POST("/tasks", middleware.RateLimitMiddleware(1, 3), controller.PostTask())
+	router.POST("/tasks/batch", middleware.BatchRateLimitMiddleware(2, 100), controller.BatchTasks())
 	router.PUT("/tasks/:id", middleware.RateLimitMiddleware(2, 5), controller.UpdateTask())
 	router.DELETE("/tasks/:id", middleware.RateLimitMiddleware(0.5, 1), controller.DeleteTask())
 	router.DELETE("/tasks/all", middleware.RateLimitMiddleware(0.5, 1), controller.DeleteAllTasks())
+
+	// Subtask Routes
+	router.GET("/tasks/:id/subtree", middleware.RateLimitMiddleware(3, 6), controller.GetTaskSubtree())
+	router.POST("/tasks/:id/move", middleware.RateLimitMiddleware(2, 5), controller.MoveTask())
+
+	// Dependency Routes
+	router.GET("/tasks/:id/dependencies", middleware.RateLimitMiddleware(3, 6), controller.GetDependencies())
+	router.POST("/tasks/:id/dependencies", middleware.RateLimitMiddleware(2, 5), controller.AddDependency())
+	router.DELETE("/tasks/:id/dependencies/:blocker_id", middleware.RateLimitMiddleware(2, 5), controller.RemoveDependency())
+	router.GET("/projects/:id/dependencies", middleware.RateLimitMiddleware(3, 6), controller.GetProjectDependencies())
+
+	// Ordering Routes
+	router.POST("/tasks/:id/rank", middleware.RateLimitMiddleware(3, 6), controller.RankTask())
+
+	// Undo Routes
+	router.POST("/undo", middleware.RateLimitMiddleware(1, 3), controller.Undo())
+	router.POST("/redo", middleware.RateLimitMiddleware(1, 3), controller.Redo())
+
+	// History Routes
+	router.GET("/tasks/:id/history", middleware.RateLimitMiddleware(3, 6), controller.GetTaskHistory())
+	router.POST("/tasks/:id/history/:number/revert", middleware.RateLimitMiddleware(1, 3), controller.RevertTask())
+
+	// Comment Routes
+	router.GET("/tasks/:id/comments", middleware.RateLimitMiddleware(3, 6), controller.GetComments())
+	router.GET("/tasks/:id/comments/:comment_id", middleware.RateLimitMiddleware(3, 6), controller.GetComment())
+	router.POST("/tasks/:id/comments", middleware.RateLimitMiddleware(2, 5), controller.PostComment())
+	router.PUT("/tasks/:id/comments/:comment_id", middleware.RateLimitMiddleware(2, 5), controller.UpdateComment())
+	router.DELETE("/tasks/:id/comments/:comment_id", middleware.RateLimitMiddleware(1, 3), controller.DeleteComment())
+
+	// Attachment Routes
+	router.GET("/tasks/:id/attachments", middleware.RateLimitMiddleware(3, 6), controller.GetAttachments())
+	router.GET("/tasks/:id/attachments/:attachment_id", middleware.RateLimitMiddleware(3, 6), controller.GetAttachment())
+	router.POST("/tasks/:id/attachments", middleware.RateLimitMiddleware(0.5, 2), controller.PostAttachment())
+	router.DELETE("/tasks/:id/attachments/:attachment_id", middleware.RateLimitMiddleware(1, 3), controller.DeleteAttachment())
+
+	// Assignment Routes
+	router.PUT("/tasks/:id/assignee", middleware.RateLimitMiddleware(1, 3), controller.AssignTask())
+	router.DELETE("/tasks/:id/assignee", middleware.RateLimitMiddleware(1, 3), controller.UnassignTask())
+	router.POST("/tasks/:id/assignment/accept", middleware.RateLimitMiddleware(1, 3), controller.AcceptAssignment())
+	router.POST("/tasks/:id/assignment/decline", middleware.RateLimitMiddleware(1, 3), controller.DeclineAssignment())
+
+	// Share Routes
+	router.GET("/shared", middleware.RateLimitMiddleware(3, 6), controller.GetSharedWithMe())
+	router.GET("/tasks/:id/shares", middleware.RateLimitMiddleware(3, 6), controller.GetShares(model.ShareTask))
+	router.POST("/tasks/:id/shares", middleware.RateLimitMiddleware(1, 3), controller.GrantShare(model.ShareTask))
+	router.PUT("/tasks/:id/shares/:user_id", middleware.RateLimitMiddleware(1, 3), controller.UpdateShare(model.ShareTask))
+	router.DELETE("/tasks/:id/shares/:user_id", middleware.RateLimitMiddleware(1, 3), controller.RevokeShare(model.ShareTask))
+	router.GET("/projects/:id/shares", middleware.RateLimitMiddleware(3, 6), controller.GetShares(model.ShareProject))
+	router.POST("/projects/:id/shares", middleware.RateLimitMiddleware(1, 3), controller.GrantShare(model.ShareProject))
+	router.PUT("/projects/:id/shares/:user_id", middleware.RateLimitMiddleware(1, 3), controller.UpdateShare(model.ShareProject))
+	router.DELETE("/projects/:id/shares/:user_id", middleware.RateLimitMiddleware(1, 3), controller.RevokeShare(model.ShareProject))
+
+	// Recurrence Routes
+	router.GET("/tasks/:id/occurrences", middleware.RateLimitMiddleware(3, 6), controller.GetTaskOccurrences())
+
+	// Checklist Routes
+	router.POST("/tasks/:id/checklist", middleware.RateLimitMiddleware(2, 5), controller.AddChecklistItem())
+	router.PATCH("/tasks/:id/checklist/:item_id", middleware.RateLimitMiddleware(3, 6), controller.UpdateChecklistItem())
+	router.PUT("/tasks/:id/checklist/order", middleware.RateLimitMiddleware(2, 5), controller.ReorderChecklist())
+	router.DELETE("/tasks/:id/checklist/:item_id", middleware.RateLimitMiddleware(2, 5), controller.DeleteChecklistItem())
+
+	// Project Routes
+	router.GET("/projects", middleware.RateLimitMiddleware(5, 10), controller.GetProjects())
+	router.GET("/projects/:id", middleware.RateLimitMiddleware(3, 6), controller.GetProjectByID())
+	router.POST("/projects", middleware.RateLimitMiddleware(1, 3), controller.PostProject())
+	router.PUT("/projects/:id", middleware.RateLimitMiddleware(2, 5), controller.UpdateProject())
+	router.DELETE("/projects/:id", middleware.RateLimitMiddleware(0.5, 1), controller.DeleteProject())
+
+	// Board Routes
+	router.GET("/projects/:id/board", middleware.RateLimitMiddleware(3, 6), controller.GetBoard())
+	router.GET("/projects/:id/board/tasks", middleware.RateLimitMiddleware(3, 6), controller.GetBoardTasks())
+	router.PUT("/projects/:id/board", middleware.RateLimitMiddleware(1, 3), controller.PutBoard())
+	router.DELETE("/projects/:id/board", middleware.RateLimitMiddleware(0.5, 1), controller.DeleteBoard())
+
+	// Tag Routes
+	router.GET("/tags", middleware.RateLimitMiddleware(5, 10), controller.GetTags())
+	router.POST("/tags", middleware.RateLimitMiddleware(1, 3), controller.PostTag())
+	router.PUT("/tags/:id", middleware.RateLimitMiddleware(1, 3), controller.UpdateTag())
+	router.POST("/tags/:id/merge", middleware.RateLimitMiddleware(0.5, 1), controller.MergeTag())
+	router.DELETE("/tags/:id", middleware.RateLimitMiddleware(0.5, 1), controller.DeleteTag())
+
+	// Trash Routes
+	router.GET("/trash", middleware.RateLimitMiddleware(5, 10), controller.GetTrash())
+	router.POST("/trash/:id/restore", middleware.RateLimitMiddleware(2, 5), controller.RestoreTask())
+	router.DELETE("/trash/:id", middleware.RateLimitMiddleware(0.5, 1), controller.PurgeTask())
+	router.DELETE("/trash", middleware.RateLimitMiddleware(0.2, 1), controller.EmptyTrash())
+
+	// Saved Filter Routes
+	router.GET("/filters", middleware.RateLimitMiddleware(5, 10), controller.GetSavedFilters())
+	router.GET("/filters/:id", middleware.RateLimitMiddleware(3, 6), controller.GetSavedFilterByID())
+	router.POST("/filters", middleware.RateLimitMiddleware(1, 3), controller.PostSavedFilter())
+	router.PUT("/filters/:id", middleware.RateLimitMiddleware(2, 5), controller.UpdateSavedFilter())
+	router.DELETE("/filters/:id", middleware.RateLimitMiddleware(0.5, 1), controller.DeleteSavedFilter())
 }
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	database "task-manager/server/database"
+)
+
+// ErrNotFound - Returned when a blob does not exist in the store
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore keeps the contents of uploaded files, addressed by a key chosen by the caller
+type BlobStore interface {
+	// Put - Stores everything read from r under key and returns the number of bytes written
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Open - Returns a reader for the blob stored under key, which the caller must close
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete - Removes the blob stored under key. Deleting a missing blob is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// Attachments - The store task attachments are kept in, set up by main before the server starts
+var Attachments BlobStore
+
+// NewBlobStore - Creates the store of the given kind: "gridfs" (the default) keeps blobs in MongoDB
+// and "local" keeps them as files under dir
+func NewBlobStore(kind string, dir string) (BlobStore, error) {
+	switch kind {
+	case "", "gridfs":
+		return NewGridFSStore(database.MongoClient.Database("task_manager"), "attachments")
+	case "local":
+		if dir == "" {
+			dir = "attachments"
+		}
+		return NewLocalStore(dir)
+	}
+	return nil, fmt.Errorf("unknown blob storage %q, expected gridfs or local", kind)
+}